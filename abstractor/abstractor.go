@@ -10,6 +10,53 @@ type GadgetDefinition interface {
 	DefineGadget(api frontend.API) interface{}
 }
 
+// GadgetDefinitionT is the type-safe version of GadgetDefinition: the
+// outputs of gadgets implementing it are returned by CallT without casting.
+type GadgetDefinitionT[T any] interface {
+	DefineGadget(api frontend.API) T
+}
+
+// Untyped adapts a GadgetDefinitionT to the GadgetDefinition interface.
+type Untyped[T any] struct {
+	Gadget GadgetDefinitionT[T]
+}
+
+func (g Untyped[T]) DefineGadget(api frontend.API) interface{} {
+	return g.Gadget.DefineGadget(api)
+}
+
+func (g Untyped[T]) unwrap() any {
+	return g.Gadget
+}
+
+// untyped is implemented by the instances of Untyped. The method is
+// unexported so that the gadgets of users can't implement it.
+type untyped interface {
+	unwrap() any
+}
+
+// UnwrapGadget returns the gadget adapted by Untyped, or `gadget` itself
+// if it isn't adapted.
+func UnwrapGadget(gadget any) any {
+	if u, ok := gadget.(untyped); ok {
+		return u.unwrap()
+	}
+	return gadget
+}
+
+// Inliner is implemented by the gadgets which choose whether the extractor
+// exports them in the code of their callers, in place of a Lean definition.
+type Inliner interface {
+	Inline() bool
+}
+
 type API interface {
+	frontend.API
 	Call(gadget GadgetDefinition) interface{}
 }
+
+// AbsCircuit is a circuit defined using the abstractor API.
+// The Define method required by gnark can be derived with Concretize.
+type AbsCircuit interface {
+	AbsDefine(api API) error
+}
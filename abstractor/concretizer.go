@@ -0,0 +1,69 @@
+package abstractor
+
+import (
+	"fmt"
+
+	"github.com/consensys/gnark/frontend"
+)
+
+// Concretize calls `circuit.AbsDefine` using `api`. It is meant to be used
+// to implement the Define method of an AbsCircuit:
+//
+//	func (circuit *MyCircuit) Define(api frontend.API) error {
+//		return abstractor.Concretize(api, circuit)
+//	}
+//
+// When `api` is the extractor, the gadgets are exported to Lean, otherwise
+// they are defined inline as a plain gnark circuit.
+func Concretize(api frontend.API, circuit AbsCircuit) error {
+	if abs, ok := api.(API); ok {
+		return circuit.AbsDefine(abs)
+	}
+	return circuit.AbsDefine(&Concretizer{api})
+}
+
+// Concretizer implements API on top of a gnark frontend.API by
+// calling the gadget definitions directly.
+type Concretizer struct {
+	frontend.API
+}
+
+func (c *Concretizer) Call(gadget GadgetDefinition) interface{} {
+	return gadget.DefineGadget(c)
+}
+
+// Commit forwards the call to the wrapped frontend.API. It is needed by
+// gnark std gadgets which check if `api` implements frontend.Committer.
+func (c *Concretizer) Commit(v ...frontend.Variable) (frontend.Variable, error) {
+	if committer, ok := c.API.(frontend.Committer); ok {
+		return committer.Commit(v...)
+	}
+	return nil, fmt.Errorf("%T doesn't implement frontend.Committer", c.API)
+}
+
+// keyValueStore is the interface used by gnark std gadgets to share
+// state in a circuit
+type keyValueStore interface {
+	SetKeyValue(key, value any)
+	GetKeyValue(key any) (value any)
+}
+
+// SetKeyValue forwards the call to the wrapped frontend.API
+func (c *Concretizer) SetKeyValue(key, value any) {
+	if kv, ok := c.API.(keyValueStore); ok {
+		kv.SetKeyValue(key, value)
+		return
+	}
+	panic(fmt.Sprintf("%T doesn't implement a key-value store", c.API))
+}
+
+// GetKeyValue forwards the call to the wrapped frontend.API
+func (c *Concretizer) GetKeyValue(key any) any {
+	if kv, ok := c.API.(keyValueStore); ok {
+		return kv.GetKeyValue(key)
+	}
+	panic(fmt.Sprintf("%T doesn't implement a key-value store", c.API))
+}
+
+var _ API = &Concretizer{}
+var _ frontend.Committer = &Concretizer{}
@@ -2,7 +2,8 @@
 // The Call functions are used to call gadgets and get their returnd object.
 // These methods are prepared for doing automated casting from interface{}.
 // Alternatively it's possible to do manual casting by calling
-// abstractor.Call() and casting the result to the needed type.
+// abstractor.Call() and casting the result to the needed type,
+// or to use CallT with gadgets implementing GadgetDefinitionT.
 package abstractor
 
 import (
@@ -20,7 +21,11 @@ func Call(api frontend.API, gadget GadgetDefinition) frontend.Variable {
 
 // CallVoid is used to call a Gadget which doesn't return anything
 func CallVoid(api frontend.API, gadget GadgetDefinition) {
-	Call(api, gadget)
+	if abs, ok := api.(API); ok {
+		abs.Call(gadget)
+	} else {
+		gadget.DefineGadget(api)
+	}
 }
 
 // Call1 is used to call a Gadget which returns []frontend.Variable (i.e. `Vector F d` in Lean)
@@ -39,3 +44,18 @@ func Call2(api frontend.API, gadget GadgetDefinition) [][]frontend.Variable {
 func Call3(api frontend.API, gadget GadgetDefinition) [][][]frontend.Variable {
 	return Call(api, gadget).([][][]frontend.Variable)
 }
+
+// CallT is used to call a Gadget implementing GadgetDefinitionT. It returns
+// the outputs of the gadget with their own type, whatever the nesting depth.
+func CallT[T any](api frontend.API, gadget GadgetDefinitionT[T]) T {
+	if abs, ok := api.(API); ok {
+		res := abs.Call(Untyped[T]{gadget})
+		if res == nil {
+			var zero T
+			return zero
+		}
+		return res.(T)
+	} else {
+		return gadget.DefineGadget(api)
+	}
+}
@@ -0,0 +1,248 @@
+// This file contains the lowering of gnark blueprints into App entries.
+// Blueprints exchange data with the circuit using wire IDs: each Operand
+// passed to ToCanonicalVariable is assigned a new wire, and the outputs
+// of an instruction are wires that can be read with InternalVariable.
+package extractor
+
+import (
+	"math/big"
+
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/frontend"
+)
+
+// lookupHintName is the name given to the ExHint generated by
+// instructions of constraint.BlueprintLookupHint
+const lookupHintName = "constraint.BlueprintLookupHint"
+
+// AddBlueprint registers `b` if it's one of the supported gnark blueprints.
+// It panics for the unsupported ones.
+func (ce *CodeExtractor) AddBlueprint(b constraint.Blueprint) constraint.BlueprintID {
+	switch b.(type) {
+	case *constraint.BlueprintLookupHint, constraint.BlueprintHint, constraint.BlueprintR1C, constraint.BlueprintSparseR1C:
+		ce.blueprints = append(ce.blueprints, b)
+		return constraint.BlueprintID(len(ce.blueprints) - 1)
+	default:
+		fail(ErrUnsupportedAPI, b, "Blueprint of type %T isn't supported", b)
+		return 0
+	}
+}
+
+// AddInstruction decodes `calldata` according to the blueprint `bID` and
+// adds the equivalent App entries to the Code. It returns the wire IDs
+// of the outputs of the instruction.
+func (ce *CodeExtractor) AddInstruction(bID constraint.BlueprintID, calldata []uint32) []uint32 {
+	if int(bID) >= len(ce.blueprints) {
+		fail(ErrInvalidArgument, bID, "Blueprint %d hasn't been added", bID)
+	}
+	inst := constraint.Instruction{Calldata: calldata}
+	switch b := ce.blueprints[bID].(type) {
+	case *constraint.BlueprintLookupHint:
+		return ce.addLookupHint(b, inst)
+	case constraint.BlueprintHint:
+		var h constraint.HintMapping
+		b.DecompressHint(&h, inst)
+		inputs := make([]frontend.Variable, len(h.Inputs))
+		for i, l := range h.Inputs {
+			inputs[i] = ce.linearExpression(l)
+		}
+		outs, err := ce.NewHintForId(h.HintID, int(h.OutputRange.End-h.OutputRange.Start), inputs...)
+		if err != nil {
+			panic(err)
+		}
+		// The output wires of generic hints are part of the calldata
+		for i, out := range outs {
+			ce.setWire(h.OutputRange.Start+uint32(i), out.(Operand))
+		}
+		return []uint32{}
+	case constraint.BlueprintR1C:
+		var c constraint.R1C
+		b.DecompressR1C(&c, inst)
+		l := ce.linearExpression(c.L)
+		r := ce.linearExpression(c.R)
+		ce.AssertIsEqual(ce.Mul(l, r), ce.linearExpression(c.O))
+		return []uint32{}
+	case constraint.BlueprintSparseR1C:
+		var c constraint.SparseR1C
+		b.DecompressSparseR1C(&c, inst)
+		xa := ce.wire(c.XA)
+		xb := ce.wire(c.XB)
+		xc := ce.outputWire(c.XC)
+		res := ce.sum(
+			ce.term(c.QL, xa),
+			ce.term(c.QR, xb),
+			ce.term(c.QO, xc),
+			ce.term(c.QM, xa, xb),
+			ce.term(c.QC),
+		)
+		ce.AssertIsEqual(res, 0)
+		return []uint32{}
+	default:
+		fail(ErrUnsupportedAPI, b, "Blueprint of type %T isn't supported", b)
+		return nil
+	}
+}
+
+// addLookupHint lowers an instruction of BlueprintLookupHint to an ExHint
+// taking the entries of the table and the queried indices. The lookup
+// itself doesn't add constraints: they are added by the log-derivative
+// argument in gnark/std/lookup/logderivlookup.
+func (ce *CodeExtractor) addLookupHint(b *constraint.BlueprintLookupHint, inst constraint.Instruction) []uint32 {
+	nbEntries := int(inst.Calldata[1])
+	nbQueries := int(inst.Calldata[2])
+
+	entries := make([]frontend.Variable, nbEntries)
+	offset := 0
+	for i := range entries {
+		l, delta := readLinearExpression(b.EntriesCalldata[offset:])
+		entries[i] = ce.linearExpression(l)
+		offset += delta
+	}
+
+	queries := make([]frontend.Variable, nbQueries)
+	offset = 3
+	for i := range queries {
+		l, delta := readLinearExpression(inst.Calldata[offset:])
+		queries[i] = ce.linearExpression(l)
+		offset += delta
+	}
+
+	outs, err := ce.addHint(ExHint{Name: lookupHintName, NbOutputs: nbQueries}, entries, queries)
+	if err != nil {
+		panic(err)
+	}
+	wires := make([]uint32, len(outs))
+	for i, out := range outs {
+		wires[i] = ce.newWire(out.(Operand))
+	}
+	return wires
+}
+
+// readLinearExpression decodes the linear expression at the beginning
+// of `calldata` and returns it with the number of elements read.
+func readLinearExpression(calldata []uint32) (constraint.LinearExpression, int) {
+	n := int(calldata[0])
+	l := make(constraint.LinearExpression, n)
+	for i := range l {
+		l[i] = constraint.Term{CID: calldata[1+2*i], VID: calldata[2+2*i]}
+	}
+	return l, 1 + 2*n
+}
+
+// linearExpression returns the Operand equivalent to `l`
+func (ce *CodeExtractor) linearExpression(l constraint.LinearExpression) frontend.Variable {
+	terms := make([]frontend.Variable, len(l))
+	for i, t := range l {
+		terms[i] = ce.term(t.CID, ce.wire(t.VID))
+	}
+	return ce.sum(terms...)
+}
+
+// term returns the product of the coefficient `cID` and `ops`.
+// It returns nil if the coefficient is zero.
+func (ce *CodeExtractor) term(cID uint32, ops ...frontend.Variable) frontend.Variable {
+	switch {
+	case cID == constraint.CoeffIdZero:
+		return nil
+	case len(ops) == 0:
+		return ce.coeff(cID)
+	case cID == constraint.CoeffIdOne && len(ops) == 1:
+		return ops[0]
+	case cID == constraint.CoeffIdOne:
+		return ce.Mul(ops[0], ops[1], ops[2:]...)
+	default:
+		return ce.Mul(ce.coeff(cID), ops[0], ops[1:]...)
+	}
+}
+
+// sum returns the sum of the non nil `terms`
+func (ce *CodeExtractor) sum(terms ...frontend.Variable) frontend.Variable {
+	nonZero := []frontend.Variable{}
+	for _, t := range terms {
+		if t != nil {
+			nonZero = append(nonZero, t)
+		}
+	}
+	switch len(nonZero) {
+	case 0:
+		return Const{big.NewInt(0)}
+	case 1:
+		return nonZero[0]
+	default:
+		return ce.Add(nonZero[0], nonZero[1], nonZero[2:]...)
+	}
+}
+
+// coeff returns the value of the coefficient `cID`. The extractor doesn't have
+// a table of coefficients, therefore only the predefined ones are supported.
+func (ce *CodeExtractor) coeff(cID uint32) Const {
+	switch cID {
+	case constraint.CoeffIdZero:
+		return Const{big.NewInt(0)}
+	case constraint.CoeffIdOne:
+		return Const{big.NewInt(1)}
+	case constraint.CoeffIdTwo:
+		return Const{big.NewInt(2)}
+	case constraint.CoeffIdMinusOne:
+		return Const{big.NewInt(-1)}
+	case constraint.CoeffIdMinusTwo:
+		return Const{big.NewInt(-2)}
+	default:
+		fail(ErrUnsupportedAPI, cID, "Coefficient %d isn't supported in blueprints", cID)
+		return Const{}
+	}
+}
+
+// newWire assigns a new wire ID to `op`
+func (ce *CodeExtractor) newWire(op Operand) uint32 {
+	id := ce.nextWire
+	ce.setWire(id, op)
+	return id
+}
+
+// setWire assigns the wire `id` to `op`
+func (ce *CodeExtractor) setWire(id uint32, op Operand) {
+	if ce.wires == nil {
+		ce.wires = make(map[uint32]Operand)
+	}
+	ce.wires[id] = op
+	if id >= ce.nextWire {
+		ce.nextWire = id + 1
+	}
+}
+
+// wire returns the Operand of the wire `id`. It fails if the wire
+// hasn't been assigned.
+func (ce *CodeExtractor) wire(id uint32) Operand {
+	op, ok := ce.wires[id]
+	if !ok {
+		fail(ErrInvalidArgument, id, "Wire %d hasn't been assigned", id)
+	}
+	return op
+}
+
+// outputWire returns the Operand of the wire `id`, which is the output of
+// a constraint (i.e. the XC of a BlueprintSparseR1CMul). If it hasn't been
+// assigned yet, it's existentially quantified.
+func (ce *CodeExtractor) outputWire(id uint32) Operand {
+	if op, ok := ce.wires[id]; ok {
+		return op
+	}
+	outs, err := ce.addHint(ExHint{Name: "wire", NbOutputs: 1})
+	if err != nil {
+		panic(err)
+	}
+	ce.setWire(id, outs[0].(Operand))
+	return outs[0].(Operand)
+}
+
+func (ce *CodeExtractor) InternalVariable(wireID uint32) frontend.Variable {
+	return ce.wire(wireID)
+}
+
+// ToCanonicalVariable assigns a new wire to `variable` and returns it
+// as a term with coefficient one.
+func (ce *CodeExtractor) ToCanonicalVariable(variable frontend.Variable) frontend.CanonicalVariable {
+	op := sanitizeVars(variable)[0]
+	return constraint.Term{CID: constraint.CoeffIdOne, VID: ce.newWire(op)}
+}
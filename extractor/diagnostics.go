@@ -0,0 +1,116 @@
+// This file contains the diagnostics reported during the extraction,
+// which are returned by Extract and ExtractGadget, collected with
+// WithDiagnostics or sent to a log/slog handler with WithSlogHandler.
+package extractor
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// Severity is the importance of a Diagnostic
+type Severity int
+
+const (
+	SeverityInfo Severity = iota
+	SeverityWarning
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityInfo:
+		return "info"
+	case SeverityWarning:
+		return "warning"
+	default:
+		return fmt.Sprintf("severity %d", int(s))
+	}
+}
+
+// Diagnostic is a message about the extraction which doesn't stop it.
+// Gadget is the name of the gadget being defined and Field is the path
+// of the field of the circuit or gadget (i.e. `Proof[2]`), if any.
+type Diagnostic struct {
+	Severity Severity
+	Message  string
+	Gadget   string
+	Field    string
+}
+
+func (d Diagnostic) String() string {
+	msg := fmt.Sprintf("%s: %s", d.Severity, d.Message)
+	if d.Field != "" {
+		msg = fmt.Sprintf("%s (field %s)", msg, d.Field)
+	}
+	if d.Gadget != "" {
+		msg = fmt.Sprintf("%s in gadget %s", msg, d.Gadget)
+	}
+	return msg
+}
+
+// Diagnostics is the list of the diagnostics of an extraction
+type Diagnostics []Diagnostic
+
+// Warnings returns the diagnostics with SeverityWarning
+func (d Diagnostics) Warnings() Diagnostics {
+	return d.filter(SeverityWarning)
+}
+
+// Infos returns the diagnostics with SeverityInfo
+func (d Diagnostics) Infos() Diagnostics {
+	return d.filter(SeverityInfo)
+}
+
+func (d Diagnostics) filter(severity Severity) Diagnostics {
+	res := Diagnostics{}
+	for _, diagnostic := range d {
+		if diagnostic.Severity == severity {
+			res = append(res, diagnostic)
+		}
+	}
+	return res
+}
+
+// level returns the slog.Level of `severity`
+func level(severity Severity) slog.Level {
+	if severity == SeverityWarning {
+		return slog.LevelWarn
+	}
+	return slog.LevelInfo
+}
+
+// report records a diagnostic of `gadget`. In strict mode, warnings
+// stop the extraction with an error of kind ErrWarning.
+func (ce *CodeExtractor) report(severity Severity, gadget string, field string, format string, args ...any) {
+	d := Diagnostic{severity, fmt.Sprintf(format, args...), gadget, field}
+	ce.diagnostics = append(ce.diagnostics, d)
+	if ce.Config.Diagnostics != nil {
+		*ce.Config.Diagnostics = append(*ce.Config.Diagnostics, d)
+	}
+	if handler := ce.Config.Handler; handler != nil && handler.Enabled(context.Background(), level(severity)) {
+		record := slog.NewRecord(time.Now(), level(severity), d.Message, 0)
+		if d.Gadget != "" {
+			record.AddAttrs(slog.String("gadget", d.Gadget))
+		}
+		if d.Field != "" {
+			record.AddAttrs(slog.String("field", d.Field))
+		}
+		// Errors of the handler aren't errors of the extraction
+		_ = handler.Handle(context.Background(), record)
+	}
+	if severity == SeverityWarning && ce.Config.Strict {
+		fail(ErrWarning, field, "%s", d.Message)
+	}
+}
+
+// info records a diagnostic with SeverityInfo of the gadget being defined
+func (ce *CodeExtractor) info(field string, format string, args ...any) {
+	ce.report(SeverityInfo, ce.gadget, field, format, args...)
+}
+
+// warn records a diagnostic with SeverityWarning of the gadget being defined
+func (ce *CodeExtractor) warn(field string, format string, args ...any) {
+	ce.report(SeverityWarning, ce.gadget, field, format, args...)
+}
@@ -0,0 +1,128 @@
+// This file contains the errors returned by the public API when the
+// extraction fails.
+package extractor
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+var (
+	// ErrUnsupportedAPI is the kind of the errors caused by gnark features
+	// which the extractor doesn't support (i.e. unsupported blueprints)
+	ErrUnsupportedAPI = errors.New("unsupported API")
+	// ErrUnsupportedType is the kind of the errors caused by values which
+	// can't be exported to Lean (i.e. anonymous structs)
+	ErrUnsupportedType = errors.New("unsupported type")
+	// ErrInvalidNamespace is the kind of the errors caused by namespaces
+	// which aren't valid Lean identifiers
+	ErrInvalidNamespace = errors.New("invalid namespace")
+	// ErrInvalidArgument is the kind of the errors caused by invalid
+	// arguments of the API methods (i.e. a negative number of bits)
+	ErrInvalidArgument = errors.New("invalid argument")
+	// ErrGadgetConflict is the kind of the errors caused by gadgets with the
+	// same name and different bodies (i.e. with different float fields)
+	ErrGadgetConflict = errors.New("gadget conflict")
+	// ErrWarning is the kind of the errors caused by warnings in strict mode
+	ErrWarning = errors.New("warning")
+)
+
+// GadgetFrame is a gadget being defined when an error occurred
+type GadgetFrame struct {
+	Name string
+	// Location is the file and line of the call to the gadget
+	Location string
+}
+
+// ExtractionError is the error returned by the public API when the
+// extraction fails. Kind is one of the Err sentinels, if the cause
+// of the error is known, and it's matched by errors.Is.
+type ExtractionError struct {
+	Kind    error
+	Message string
+	// Gadgets is the stack of the gadgets being defined, outermost first
+	Gadgets []GadgetFrame
+	// Operand is the value which caused the error, if any
+	Operand any
+	// Cause is the error which caused the panic, if any
+	Cause error
+}
+
+func (e *ExtractionError) Error() string {
+	msg := e.Message
+	if e.Kind != nil {
+		msg = fmt.Sprintf("%s: %s", e.Kind, msg)
+	}
+	if len(e.Gadgets) > 0 {
+		frames := make([]string, len(e.Gadgets))
+		for i, g := range e.Gadgets {
+			frames[i] = fmt.Sprintf("%s (%s)", g.Name, g.Location)
+		}
+		msg = fmt.Sprintf("%s in gadget %s", msg, strings.Join(frames, " > "))
+	}
+	return msg
+}
+
+func (e *ExtractionError) Unwrap() []error {
+	errs := []error{}
+	for _, err := range []error{e.Kind, e.Cause} {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// fail stops the extraction with an ExtractionError of `kind`, which
+// is returned by the public API. `operand` is the value which caused
+// the error, if any.
+func fail(kind error, operand any, format string, args ...any) {
+	panic(&ExtractionError{Kind: kind, Message: fmt.Sprintf(format, args...), Operand: operand})
+}
+
+// toExtractionError converts the value `r` recovered from a panic
+// to an ExtractionError
+func toExtractionError(r any) *ExtractionError {
+	switch r := r.(type) {
+	case *ExtractionError:
+		return r
+	case error:
+		return &ExtractionError{Message: r.Error(), Cause: r}
+	default:
+		return &ExtractionError{Message: fmt.Sprint(r)}
+	}
+}
+
+// addGadgetFrame adds `frame` at the bottom of the gadget stack of the
+// error recovered in `r` and panics again with it
+func addGadgetFrame(r any, frame GadgetFrame) {
+	err := toExtractionError(r)
+	err.Gadgets = append([]GadgetFrame{frame}, err.Gadgets...)
+	panic(err)
+}
+
+// callerLocation returns the file and line of the first caller outside of
+// the extractor, which is the call of a gadget in the user code
+func callerLocation() string {
+	extractorPkg := reflect.TypeOf(CodeExtractor{}).PkgPath()
+	internal := []string{extractorPkg + ".", path.Dir(extractorPkg) + "/abstractor.", "reflect.", "runtime."}
+	pcs := make([]uintptr, 32)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs)])
+	for {
+		frame, more := frames.Next()
+		isInternal := false
+		for _, prefix := range internal {
+			isInternal = isInternal || strings.HasPrefix(frame.Function, prefix)
+		}
+		if !isInternal {
+			return fmt.Sprintf("%s:%d", frame.File, frame.Line)
+		}
+		if !more {
+			return ""
+		}
+	}
+}
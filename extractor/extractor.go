@@ -1,9 +1,11 @@
 package extractor
 
 import (
+	"errors"
 	"fmt"
 	"math/big"
 	"reflect"
+	"slices"
 
 	"github.com/consensys/gnark-crypto/ecc"
 	"github.com/consensys/gnark/constraint"
@@ -68,6 +70,33 @@ type ProjArray struct {
 
 func (_ ProjArray) isOperand() {}
 
+// FieldProj is the access to the field `Name` of a struct
+// returned by a gadget (i.e. `gate_0.X` in Lean)
+type FieldProj struct {
+	Operand Operand
+	Name    string
+}
+
+func (_ FieldProj) isOperand() {}
+
+// ProjStruct is a struct value made of the `Values` of
+// its `Fields`. It's used for the outputs of gadgets
+// returning structs.
+type ProjStruct struct {
+	Fields []string
+	Values []Operand
+}
+
+func (_ ProjStruct) isOperand() {}
+
+// Text is used for the strings passed to Println. It's the
+// only argument of an App which isn't a variable.
+type Text struct {
+	Value string
+}
+
+func (_ Text) isOperand() {}
+
 type Op interface {
 	isOp()
 }
@@ -96,6 +125,11 @@ const (
 	OpAssertNotEq
 	OpAssertIsBool
 	OpAssertLessEqual
+	OpCommit
+	OpRangeCheck
+	OpMarkBoolean
+	OpPrintln
+	OpAssertIsCrumb
 )
 
 func (_ OpKind) isOp() {}
@@ -118,14 +152,51 @@ type ExGadget struct {
 	Extractor   *CodeExtractor
 	Fields      []schema.Field
 	Args        []ExArg
+	// Params are the sizes the gadget is generic over, if it
+	// has been generalised with WithPolymorphicGadgets
+	Params []string
+	// OutputType is the type of the outputs of generic gadgets
+	OutputType string
+	// IntParams are the integer fields the gadget is generic over, if it
+	// has been generalised with WithPolymorphicGadgets. Their values are
+	// passed after the arguments.
+	IntParams []string
+	// ints are the values of the integer fields of the gadget definition
+	ints map[string]*big.Int
+	// goType is the Go type of the gadget definition
+	goType reflect.Type
+	// inline is the result of the Inline method of the gadget, if any
+	inline bool
+	// hash identifies the Lean definition of the gadget without its name
+	hash string
 }
 
 func (g *ExGadget) isOp() {}
 
+// ExHint is the Op recorded for calls to NewHint and NewHintForId.
+// Hints don't add any constraint to the circuit, therefore their
+// outputs are exported to Lean as existentially quantified variables.
+// Name is the name of the hint function if it's known.
+type ExHint struct {
+	Name      string
+	ID        solver.HintID
+	NbOutputs int
+}
+
+func (_ ExHint) isOp() {}
+
+// ExRemoved is the Op of the apps removed by WithDeadGateElimination.
+// Op is the original Op, which is exported as a comment.
+type ExRemoved struct {
+	Op Op
+}
+
+func (_ ExRemoved) isOp() {}
+
 func (g *ExGadget) Call(gadget abstractor.GadgetDefinition) interface{} {
 	args := []frontend.Variable{}
 
-	rv := reflect.Indirect(reflect.ValueOf(gadget))
+	rv := reflect.Indirect(reflect.ValueOf(abstractor.UnwrapGadget(gadget)))
 	rt := rv.Type()
 	// Looping through the circuit fields only.
 	for i := 0; i < rt.NumField(); i++ {
@@ -133,9 +204,16 @@ func (g *ExGadget) Call(gadget abstractor.GadgetDefinition) interface{} {
 		v := rv.FieldByName(fld.Name)
 		switch v.Kind() {
 		case reflect.Slice:
-			arg := flattenSlice(v)
-			if len(arg) != 0 {
-				args = append(args, arg)
+			// Ragged slices are passed as multiple arguments
+			for _, part := range splitRagged(fld.Name, v) {
+				if isStruct(part.Value) {
+					args = append(args, part.Value.Interface())
+					continue
+				}
+				arg := flattenSlice(part.Value)
+				if len(arg) != 0 {
+					args = append(args, arg)
+				}
 			}
 		case reflect.Array:
 			// I can't convert from array to slice using Reflect because
@@ -149,6 +227,10 @@ func (g *ExGadget) Call(gadget abstractor.GadgetDefinition) interface{} {
 			}
 		case reflect.Interface:
 			args = append(args, v.Elem().Interface().(frontend.Variable))
+		case reflect.Struct:
+			if len(exportedFields(v)) != 0 {
+				args = append(args, v.Interface())
+			}
 		}
 	}
 	gate := g.Extractor.AddApp(g, args...)
@@ -161,14 +243,21 @@ func (ce *CodeExtractor) Call(gadget abstractor.GadgetDefinition) interface{} {
 	// Deep copying `gadget` because `DefineGadget` needs to modify the gadget fields.
 	// This was done as a replacement to the initial method of declaring gadgets using
 	// a direct call to `Define Gadget` within the circuit and then calling GadgetDefinition.Call
-	clonedGadget := cloneGadget(gadget)
+	clonedGadget := cloneGadget(abstractor.UnwrapGadget(gadget))
 	g := ce.DefineGadget(clonedGadget)
 	return g.Call(gadget)
 }
 
+// ExArgType is the type of a circuit or gadget argument. Size and Type
+// describe nested arrays, with Type being nil for the innermost array.
+// Struct is the name of the Lean structure of the elements, if they
+// are structs. Param is the name of the Lean parameter used in place
+// of Size in generic gadgets.
 type ExArgType struct {
-	Size int
-	Type *ExArgType
+	Size   int
+	Type   *ExArgType
+	Struct string
+	Param  string
 }
 
 type ExArg struct {
@@ -177,9 +266,19 @@ type ExArg struct {
 	Type ExArgType
 }
 
+// ExStruct is a struct used as argument or output of a circuit
+// or gadget. It's exported to Lean as a structure.
+type ExStruct struct {
+	Name   string
+	Fields []ExArg
+}
+
 type ExCircuit struct {
+	// Name is the name of the Lean definition of the circuit
+	Name    string
 	Inputs  []ExArg
 	Gadgets []ExGadget
+	Structs []ExStruct
 	Code    []App
 	Field   ecc.ID
 }
@@ -187,56 +286,104 @@ type ExCircuit struct {
 type CodeExtractor struct {
 	Code    []App
 	Gadgets []ExGadget
+	Structs []ExStruct
 	FieldID ecc.ID
+	Config  Config
+
+	// scope contains the state of the circuit or gadget being defined
+	scope scope
+	// gadget is the name of the gadget being defined, if any
+	gadget string
+	// diagnostics contains the diagnostics reported so far
+	diagnostics Diagnostics
+	// defined maps the keys of the gadgets already defined, as
+	// generated by gadgetKey, to the name of their ExGadget
+	defined map[string]string
+
+	// blueprints contains the blueprints registered with AddBlueprint
+	blueprints []constraint.Blueprint
+	// wires maps the wire IDs used by blueprints to Operand
+	wires    map[uint32]Operand
+	nextWire uint32
+}
+
+// scope is the state of CodeExtractor which is local to the circuit
+// or gadget being defined. It is reset every time a gadget is defined
+// because Gate and Input operands refer to the Code of the gadget.
+type scope struct {
+	// booleans contains the operands known to be boolean. The value is
+	// true if the Lean code states that the operand is boolean, false if
+	// it has only been marked with MarkBoolean without Config.BooleanFacts
+	booleans map[Operand]bool
+	// deferred contains the callbacks registered with Defer
+	deferred []func(api frontend.API) error
+	// store is the key-value store used by gnark std gadgets
+	store map[any]any
 }
 
 func (ce *CodeExtractor) AssertIsCrumb(i1 frontend.Variable) {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (ce *CodeExtractor) AddBlueprint(b constraint.Blueprint) constraint.BlueprintID {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (ce *CodeExtractor) AddInstruction(bID constraint.BlueprintID, calldata []uint32) []uint32 {
-	// TODO implement me
-	panic("implement me")
+	ce.AddApp(OpAssertIsCrumb, i1)
 }
 
 func (ce *CodeExtractor) NewHintForId(
 	id solver.HintID, nbOutputs int, inputs ...frontend.Variable,
 ) ([]frontend.Variable, error) {
-	// TODO implement me
-	panic("implement me")
+	name := ""
+	if f := solver.GetRegisteredHint(id); f != nil {
+		name = hintName(f)
+	}
+	return ce.addHint(ExHint{name, id, nbOutputs}, inputs...)
 }
 
+// Defer queues `cb` to be called when the definition of the current
+// circuit or gadget is completed
 func (ce *CodeExtractor) Defer(cb func(api frontend.API) error) {
-	// TODO implement me
-	panic("implement me")
+	ce.scope.deferred = append(ce.scope.deferred, cb)
+}
+
+// runDeferred calls the callbacks registered with Defer in order.
+// Callbacks registered while running the queue are called too.
+func (ce *CodeExtractor) runDeferred() error {
+	for len(ce.scope.deferred) > 0 {
+		cb := ce.scope.deferred[0]
+		ce.scope.deferred = ce.scope.deferred[1:]
+		if err := cb(ce); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-func (ce *CodeExtractor) InternalVariable(wireID uint32) frontend.Variable {
-	// TODO implement me
-	panic("implement me")
+// SetKeyValue implements the key-value store used by gnark std gadgets
+// to share a single instance of a component (i.e. multicommit) per circuit.
+func (ce *CodeExtractor) SetKeyValue(key, value any) {
+	if !reflect.TypeOf(key).Comparable() {
+		fail(ErrInvalidArgument, key, "key type not comparable")
+	}
+	if ce.scope.store == nil {
+		ce.scope.store = make(map[any]any)
+	}
+	ce.scope.store[key] = value
 }
 
-func (ce *CodeExtractor) ToCanonicalVariable(variable frontend.Variable) frontend.CanonicalVariable {
-	// TODO implement me
-	panic("implement me")
+// GetKeyValue returns the value stored with SetKeyValue for `key`
+func (ce *CodeExtractor) GetKeyValue(key any) any {
+	if !reflect.TypeOf(key).Comparable() {
+		fail(ErrInvalidArgument, key, "key type not comparable")
+	}
+	return ce.scope.store[key]
 }
 
 func (ce *CodeExtractor) SetGkrInfo(info constraint.GkrInfo) error {
-	// TODO implement me
-	panic("implement me")
+	fail(ErrUnsupportedAPI, info, "SetGkrInfo isn't supported")
+	return nil
 }
 
 func sanitizeVars(args ...frontend.Variable) []Operand {
 	ops := []Operand{}
 	for _, arg := range args {
 		switch arg.(type) {
-		case Input, Gate, Proj, Const:
+		case Input, Gate, Proj, FieldProj, Const, Text:
 			ops = append(ops, arg.(Operand))
 		case Integer:
 			ops = append(ops, arg.(Operand))
@@ -271,8 +418,15 @@ func sanitizeVars(args ...frontend.Variable) []Operand {
 			// passed to gadgets
 			ops = append(ops, Const{big.NewInt(int64(0))})
 		default:
-			fmt.Printf("sanitizeVars invalid argument of type %T\n%#v\n", arg, arg)
-			panic("sanitizeVars invalid argument")
+			if isStruct(reflect.ValueOf(arg)) {
+				ops = append(ops, structOperand(reflect.ValueOf(arg)))
+				continue
+			}
+			if reflect.ValueOf(arg).Kind() == reflect.Array {
+				ops = append(ops, ProjArray{sanitizeVars(flattenSlice(reflect.ValueOf(arg))...)})
+				continue
+			}
+			fail(ErrUnsupportedType, arg, "sanitizeVars invalid argument of type %T", arg)
 		}
 	}
 	return ops
@@ -284,36 +438,185 @@ func (ce *CodeExtractor) AddApp(op Op, args ...frontend.Variable) Operand {
 	return Gate{len(ce.Code) - 1}
 }
 
+// addFoldedApp is AddApp for the arithmetic operations. When all the
+// arguments are constants, the result is computed modulo the order
+// of the field and returned as a Const in place of recording the App.
+// Otherwise, the constant arguments of Add, Sub, Mul and MulAcc are
+// folded like gnark does.
+func (ce *CodeExtractor) addFoldedApp(op OpKind, args ...frontend.Variable) frontend.Variable {
+	if res, ok := ce.foldConstants(op, args...); ok {
+		return res
+	}
+	switch op {
+	case OpAdd, OpMul:
+		return ce.foldVariadic(op, args)
+	case OpSub:
+		rest := ce.foldArgs(OpAdd, args[1:])
+		if len(rest) == 0 {
+			return args[0]
+		}
+		return ce.AddApp(op, append([]frontend.Variable{args[0]}, rest...)...)
+	case OpMulAcc:
+		a, b, c := args[0], args[1], args[2]
+		_, bConst := ce.ConstantValue(b)
+		_, cConst := ce.ConstantValue(c)
+		switch {
+		case ce.isConstant(b, 0) || ce.isConstant(c, 0):
+			return a
+		case bConst && cConst:
+			return ce.Add(a, ce.Mul(b, c))
+		case ce.isConstant(a, 0):
+			return ce.Mul(b, c)
+		}
+	}
+	return ce.AddApp(op, args...)
+}
+
+// foldVariadic records the OpAdd or OpMul of `args` with the constants
+// folded by foldArgs. If a single argument is left, it's returned
+// without recording an App.
+func (ce *CodeExtractor) foldVariadic(op OpKind, args []frontend.Variable) frontend.Variable {
+	folded := ce.foldArgs(op, args)
+	switch {
+	case op == OpMul && slices.ContainsFunc(folded, func(arg frontend.Variable) bool { return ce.isConstant(arg, 0) }):
+		return Const{big.NewInt(0)}
+	case len(folded) == 1:
+		return folded[0]
+	default:
+		return ce.AddApp(op, folded...)
+	}
+}
+
+// foldArgs replaces the constants in `args` with their sum (OpAdd) or
+// product (OpMul), at the position of the first constant. The result is
+// dropped if it's the neutral element of `op`. `args` must contain at
+// least one variable.
+func (ce *CodeExtractor) foldArgs(op OpKind, args []frontend.Variable) []frontend.Variable {
+	first := -1
+	consts := []frontend.Variable{}
+	for i, arg := range args {
+		if _, ok := ce.ConstantValue(arg); ok {
+			if first < 0 {
+				first = i
+			}
+			consts = append(consts, arg)
+		}
+	}
+	if len(consts) == 0 {
+		return args
+	}
+	value, _ := ce.foldConstants(op, consts...)
+	neutral := int64(0)
+	if op == OpMul {
+		neutral = 1
+	}
+	folded := []frontend.Variable{}
+	for i, arg := range args {
+		_, isConst := ce.ConstantValue(arg)
+		switch {
+		case !isConst:
+			folded = append(folded, arg)
+		case i != first || ce.isConstant(value, neutral):
+			// Folded into the first constant, or neutral
+		case len(consts) == 1:
+			// A single constant is kept as written
+			folded = append(folded, arg)
+		default:
+			folded = append(folded, value)
+		}
+	}
+	return folded
+}
+
+// isConstant checks that `v` is a constant equal to `value` in the field
+func (ce *CodeExtractor) isConstant(v frontend.Variable, value int64) bool {
+	c, ok := ce.ConstantValue(v)
+	if !ok {
+		return false
+	}
+	modulus := ce.FieldID.ScalarField()
+	return new(big.Int).Mod(c, modulus).Cmp(new(big.Int).Mod(big.NewInt(value), modulus)) == 0
+}
+
+// foldConstants returns the result of `op` on `args` if they are all
+// constants. Divisions by zero aren't folded and are left to the solver.
+func (ce *CodeExtractor) foldConstants(op OpKind, args ...frontend.Variable) (Operand, bool) {
+	values := make([]*big.Int, len(args))
+	for i, arg := range args {
+		value, ok := ce.ConstantValue(arg)
+		if !ok {
+			return nil, false
+		}
+		values[i] = value
+	}
+	modulus := ce.FieldID.ScalarField()
+	res := new(big.Int).Set(values[0])
+	switch op {
+	case OpAdd:
+		for _, value := range values[1:] {
+			res.Add(res, value)
+		}
+	case OpSub:
+		for _, value := range values[1:] {
+			res.Sub(res, value)
+		}
+	case OpMul:
+		for _, value := range values[1:] {
+			res.Mul(res, value)
+		}
+	case OpMulAcc:
+		res.Mul(values[1], values[2])
+		res.Add(res, values[0])
+	case OpNegative:
+		res.Neg(res)
+	case OpDiv, OpDivUnchecked, OpInverse:
+		denominator := values[0]
+		if op != OpInverse {
+			denominator = values[1]
+		} else {
+			res.SetInt64(1)
+		}
+		inverse := new(big.Int).ModInverse(new(big.Int).Mod(denominator, modulus), modulus)
+		if inverse == nil {
+			return nil, false
+		}
+		res.Mul(res, inverse)
+	default:
+		return nil, false
+	}
+	return Const{res.Mod(res, modulus)}, true
+}
+
 func (ce *CodeExtractor) Add(i1, i2 frontend.Variable, in ...frontend.Variable) frontend.Variable {
-	return ce.AddApp(OpAdd, append([]frontend.Variable{i1, i2}, in...)...)
+	return ce.addFoldedApp(OpAdd, append([]frontend.Variable{i1, i2}, in...)...)
 }
 
 func (ce *CodeExtractor) MulAcc(a, b, c frontend.Variable) frontend.Variable {
-	return ce.AddApp(OpMulAcc, a, b, c)
+	return ce.addFoldedApp(OpMulAcc, a, b, c)
 }
 
 func (ce *CodeExtractor) Neg(i1 frontend.Variable) frontend.Variable {
-	return ce.AddApp(OpNegative, i1)
+	return ce.addFoldedApp(OpNegative, i1)
 }
 
 func (ce *CodeExtractor) Sub(i1, i2 frontend.Variable, in ...frontend.Variable) frontend.Variable {
-	return ce.AddApp(OpSub, append([]frontend.Variable{i1, i2}, in...)...)
+	return ce.addFoldedApp(OpSub, append([]frontend.Variable{i1, i2}, in...)...)
 }
 
 func (ce *CodeExtractor) Mul(i1, i2 frontend.Variable, in ...frontend.Variable) frontend.Variable {
-	return ce.AddApp(OpMul, append([]frontend.Variable{i1, i2}, in...)...)
+	return ce.addFoldedApp(OpMul, append([]frontend.Variable{i1, i2}, in...)...)
 }
 
 func (ce *CodeExtractor) DivUnchecked(i1, i2 frontend.Variable) frontend.Variable {
-	return ce.AddApp(OpDivUnchecked, i1, i2)
+	return ce.addFoldedApp(OpDivUnchecked, i1, i2)
 }
 
 func (ce *CodeExtractor) Div(i1, i2 frontend.Variable) frontend.Variable {
-	return ce.AddApp(OpDiv, i1, i2)
+	return ce.addFoldedApp(OpDiv, i1, i2)
 }
 
 func (ce *CodeExtractor) Inverse(i1 frontend.Variable) frontend.Variable {
-	return ce.AddApp(OpInverse, i1)
+	return ce.addFoldedApp(OpInverse, i1)
 }
 
 func (ce *CodeExtractor) ToBinary(i1 frontend.Variable, n ...int) []frontend.Variable {
@@ -321,7 +624,7 @@ func (ce *CodeExtractor) ToBinary(i1 frontend.Variable, n ...int) []frontend.Var
 	if len(n) == 1 {
 		nbBits = n[0]
 		if nbBits < 0 {
-			panic("Number of bits in ToBinary must be > 0")
+			fail(ErrInvalidArgument, nbBits, "Number of bits in ToBinary must be > 0")
 		}
 	}
 
@@ -329,6 +632,7 @@ func (ce *CodeExtractor) ToBinary(i1 frontend.Variable, n ...int) []frontend.Var
 	outs := make([]frontend.Variable, nbBits)
 	for i := range outs {
 		outs[i] = Proj{gate, i, len(outs)}
+		ce.markBoolean(outs[i].(Operand), true)
 	}
 	return outs
 }
@@ -336,24 +640,30 @@ func (ce *CodeExtractor) ToBinary(i1 frontend.Variable, n ...int) []frontend.Var
 func (ce *CodeExtractor) FromBinary(b ...frontend.Variable) frontend.Variable {
 	// Packs in little-endian
 	if len(b) == 0 {
-		panic("FromBinary has to have at least one argument!")
+		fail(ErrInvalidArgument, nil, "FromBinary has to have at least one argument!")
 	}
 	if reflect.TypeOf(b[0]) == reflect.TypeOf([]frontend.Variable{}) {
-		panic("Pass operators to FromBinary using ellipsis")
+		fail(ErrInvalidArgument, b[0], "Pass operators to FromBinary using ellipsis")
 	}
 	return ce.AddApp(OpFromBinary, append([]frontend.Variable{}, b...)...)
 }
 
 func (ce *CodeExtractor) Xor(a, b frontend.Variable) frontend.Variable {
-	return ce.AddApp(OpXor, a, b)
+	res := ce.AddApp(OpXor, a, b)
+	ce.markBoolean(res, true)
+	return res
 }
 
 func (ce *CodeExtractor) Or(a, b frontend.Variable) frontend.Variable {
-	return ce.AddApp(OpOr, a, b)
+	res := ce.AddApp(OpOr, a, b)
+	ce.markBoolean(res, true)
+	return res
 }
 
 func (ce *CodeExtractor) And(a, b frontend.Variable) frontend.Variable {
-	return ce.AddApp(OpAnd, a, b)
+	res := ce.AddApp(OpAnd, a, b)
+	ce.markBoolean(res, true)
+	return res
 }
 
 func (ce *CodeExtractor) Select(b frontend.Variable, i1, i2 frontend.Variable) frontend.Variable {
@@ -365,7 +675,9 @@ func (ce *CodeExtractor) Lookup2(b0, b1 frontend.Variable, i0, i1, i2, i3 fronte
 }
 
 func (ce *CodeExtractor) IsZero(i1 frontend.Variable) frontend.Variable {
-	return ce.AddApp(OpIsZero, i1)
+	res := ce.AddApp(OpIsZero, i1)
+	ce.markBoolean(res, true)
+	return res
 }
 
 func (ce *CodeExtractor) Cmp(i1, i2 frontend.Variable) frontend.Variable {
@@ -380,28 +692,88 @@ func (ce *CodeExtractor) AssertIsDifferent(i1, i2 frontend.Variable) {
 	ce.AddApp(OpAssertNotEq, i1, i2)
 }
 
+// AssertIsBoolean adds a `Gates.is_bool` statement, unless the Lean code
+// already states that `i1` is boolean.
 func (ce *CodeExtractor) AssertIsBoolean(i1 frontend.Variable) {
-	ce.AddApp(OpAssertIsBool, i1)
+	op := sanitizeVars(i1)[0]
+	if ce.scope.booleans[op] {
+		return
+	}
+	ce.AddApp(OpAssertIsBool, op)
+	ce.markBoolean(op, true)
 }
 
 func (ce *CodeExtractor) AssertIsLessOrEqual(v frontend.Variable, bound frontend.Variable) {
 	ce.AddApp(OpAssertLessEqual, v, bound)
 }
 
+// Check implements frontend.Rangechecker so that the gadgets in
+// gnark/std/rangecheck use a single range gate instead of decomposing `v`.
+func (ce *CodeExtractor) Check(v frontend.Variable, bits int) {
+	if bits < 0 {
+		fail(ErrInvalidArgument, bits, "Number of bits in Check must be >= 0")
+	}
+	ce.AddApp(OpRangeCheck, v, Integer{big.NewInt(int64(bits))})
+}
+
+// Println is recorded as an App without constraints only if
+// Config.PrintlnComments is set, otherwise it's ignored.
 func (ce *CodeExtractor) Println(a ...frontend.Variable) {
-	panic("implement me")
+	if !ce.Config.PrintlnComments {
+		return
+	}
+	args := make([]frontend.Variable, len(a))
+	for i, arg := range a {
+		if str, ok := arg.(string); ok {
+			args[i] = Text{str}
+		} else {
+			args[i] = arg
+		}
+	}
+	ce.AddApp(OpPrintln, args...)
 }
 
 func (ce *CodeExtractor) Compiler() frontend.Compiler {
 	return ce
 }
 
+// markBoolean records that `v` is known to be boolean, and whether this
+// is stated in the Lean code. Only Input, Gate and Proj are tracked
+// because constants are checked by value in IsBoolean.
+func (ce *CodeExtractor) markBoolean(v Operand, stated bool) {
+	switch v.(type) {
+	case Input, Gate, Proj:
+		if ce.scope.booleans == nil {
+			ce.scope.booleans = make(map[Operand]bool)
+		}
+		ce.scope.booleans[v] = ce.scope.booleans[v] || stated
+	}
+}
+
+// MarkBoolean records that `v` is boolean without adding constraints.
+// If Config.BooleanFacts is set, the fact is also exported to Lean,
+// unless `v` is already known to be boolean.
 func (ce *CodeExtractor) MarkBoolean(v frontend.Variable) {
-	panic("implement me")
+	op := sanitizeVars(v)[0]
+	if ce.Config.BooleanFacts && !ce.IsBoolean(op) {
+		ce.AddApp(OpMarkBoolean, op)
+	}
+	ce.markBoolean(op, ce.Config.BooleanFacts)
 }
 
+// IsBoolean returns true if `v` is a constant equal to 0 or 1, or if it
+// is the result of an operation returning a boolean, or if it has been
+// asserted or marked as boolean in the current circuit or gadget.
 func (ce *CodeExtractor) IsBoolean(v frontend.Variable) bool {
-	panic("implement me")
+	switch op := sanitizeVars(v)[0].(type) {
+	case Const:
+		return op.Value.Cmp(big.NewInt(0)) == 0 || op.Value.Cmp(big.NewInt(1)) == 0
+	case Input, Gate, Proj:
+		_, ok := ce.scope.booleans[op]
+		return ok
+	default:
+		return false
+	}
 }
 
 func (ce *CodeExtractor) Field() *big.Int {
@@ -413,14 +785,35 @@ func (ce *CodeExtractor) FieldBitLen() int {
 	return ce.FieldID.ScalarField().BitLen()
 }
 
-func (ce *CodeExtractor) Commit(...frontend.Variable) (frontend.Variable, error) {
-	panic("implement me")
+// Commit records an opaque commitment to `v`. The commitment is exported
+// as an uninterpreted function of the committed variables, making the
+// assumptions on the Fiat-Shamir hash explicit in Lean.
+func (ce *CodeExtractor) Commit(v ...frontend.Variable) (frontend.Variable, error) {
+	if len(v) == 0 {
+		return nil, errors.New("Commit requires at least one variable")
+	}
+	return ce.AddApp(OpCommit, v...), nil
 }
 
 func (ce *CodeExtractor) NewHint(f solver.Hint, nbOutputs int, inputs ...frontend.Variable) (
 	[]frontend.Variable, error,
 ) {
-	panic("implement me")
+	return ce.addHint(ExHint{hintName(f), solver.GetHintID(f), nbOutputs}, inputs...)
+}
+
+// addHint records the call to a hint. The inputs are saved as
+// arguments of the App to keep track of the dependencies, while
+// the outputs are Proj of the resulting Gate.
+func (ce *CodeExtractor) addHint(hint ExHint, inputs ...frontend.Variable) ([]frontend.Variable, error) {
+	if hint.NbOutputs <= 0 {
+		return nil, fmt.Errorf("hint %s must have at least one output", hint.Name)
+	}
+	gate := ce.AddApp(hint, inputs...)
+	outs := make([]frontend.Variable, hint.NbOutputs)
+	for i := range outs {
+		outs[i] = Proj{gate, i, len(outs)}
+	}
+	return outs, nil
 }
 
 func (ce *CodeExtractor) ConstantValue(v frontend.Variable) (*big.Int, bool) {
@@ -464,28 +857,57 @@ func (ce *CodeExtractor) ConstantValue(v frontend.Variable) (*big.Int, bool) {
 	}
 }
 
-func (ce *CodeExtractor) DefineGadget(gadget abstractor.GadgetDefinition) abstractor.Gadget {
+// DefineGadget takes a pointer to a gadget implementing either
+// abstractor.GadgetDefinition or abstractor.GadgetDefinitionT.
+func (ce *CodeExtractor) DefineGadget(gadget any) abstractor.Gadget {
+	gadget = abstractor.UnwrapGadget(gadget)
+	// Errors are annotated with the stack of the gadgets being defined
+	frame := GadgetFrame{reflect.TypeOf(gadget).String(), callerLocation()}
+	if t := reflect.TypeOf(gadget); t.Kind() == reflect.Ptr {
+		frame.Name = t.Elem().Name()
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			addGadgetFrame(r, frame)
+		}
+	}()
+	parent := ce.gadget
+	ce.gadget = frame.Name
+	defer func() {
+		ce.gadget = parent
+	}()
 	if reflect.ValueOf(gadget).Kind() != reflect.Ptr {
-		panic("DefineGadget only takes pointers to the gadget")
+		fail(ErrInvalidArgument, gadget, "DefineGadget only takes pointers to the gadget")
 	}
 	schema, _ := getSchema(gadget)
-	circuitInit(gadget, schema)
+	ce.circuitInit(gadget, schema)
 	// Can't use `schema.NbPublic + schema.NbSecret`
 	// for arity because each array element is considered
 	// a parameter
-	arity := len(schema.Fields)
 	args := getExArgs(gadget, schema.Fields)
+	arity := len(args)
+	ce.addArgStructs(gadget, schema.Fields)
 
 	name := generateUniqueName(gadget, args)
+	frame.Name = name
+	ce.gadget = name
 
-	ptr_gadget := getGadgetByName(ce.Gadgets, name)
-	if ptr_gadget != nil {
-		return ptr_gadget
+	key := gadgetKey(gadget, name)
+	if defined, ok := ce.defined[key]; ok {
+		return getGadgetByName(ce.Gadgets, defined)
 	}
 
+	// The name doesn't depend on all the fields of the gadget (i.e.
+	// floats), therefore new gadgets are deduplicated by their content
+	// after the definition
 	oldCode := ce.Code
+	oldScope := ce.scope
 	ce.Code = make([]App, 0)
-	outputs := gadget.DefineGadget(ce)
+	ce.scope = scope{}
+	outputs := defineGadget(gadget, ce)
+	if err := ce.runDeferred(); err != nil {
+		panic(err)
+	}
 
 	// Handle gadgets returning nil.
 	// Without the if-statement, the nil would be replaced with (0:F)
@@ -499,12 +921,15 @@ func (ce *CodeExtractor) DefineGadget(gadget abstractor.GadgetDefinition) abstra
 	// TODO: remove `OutputsFlat` field and use only `Outputs`
 	flatOutput := []frontend.Variable{outputs}
 	vOutputs := reflect.ValueOf(outputs)
-	if vOutputs.Kind() == reflect.Slice {
+	if vOutputs.Kind() == reflect.Slice || vOutputs.Kind() == reflect.Array {
 		flatOutput = flattenSlice(vOutputs)
 	}
 
+	ce.addStructs(reflect.ValueOf(outputs))
+
 	newCode := ce.Code
 	ce.Code = oldCode
+	ce.scope = oldScope
 	exGadget := ExGadget{
 		Name:        name,
 		Arity:       arity,
@@ -514,9 +939,75 @@ func (ce *CodeExtractor) DefineGadget(gadget abstractor.GadgetDefinition) abstra
 		Extractor:   ce,
 		Fields:      schema.Fields,
 		Args:        args,
+		goType:      reflect.TypeOf(gadget).Elem(),
+		ints:        integerFields(gadget),
 	}
+	if inliner, ok := gadget.(abstractor.Inliner); ok {
+		exGadget.inline = inliner.Inline()
+	}
+	exGadget.hash = gadgetHash(exGadget)
+
+	if ce.defined == nil {
+		ce.defined = map[string]string{}
+	}
+	for _, g := range ce.Gadgets {
+		if g.hash == exGadget.hash && g.goType == exGadget.goType {
+			if g.Name != name {
+				ce.info("", "exported as %s, which has the same definition", g.Name)
+			}
+			ce.defined[key] = g.Name
+			return &g
+		}
+	}
+	if getGadgetByName(ce.Gadgets, name) != nil {
+		fail(ErrGadgetConflict, gadget, "gadget %s has different bodies for different values of its fields", name)
+	}
+	ce.defined[key] = name
 	ce.Gadgets = append(ce.Gadgets, exGadget)
 	return &exGadget
 }
 
+// addArgStructs adds the structs used by the `fields` of
+// the circuit or gadget `class`
+func (ce *CodeExtractor) addArgStructs(class any, fields []schema.Field) {
+	for _, f := range getExFields(reflect.ValueOf(class).Elem(), fields) {
+		ce.addStructs(f.Value)
+	}
+}
+
+// addStructs adds the structs in `v` to ce.Structs if they
+// aren't already present. Nested structs are added first
+// because they must be defined before use in Lean.
+func (ce *CodeExtractor) addStructs(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Interface:
+		if !v.IsNil() {
+			ce.addStructs(v.Elem())
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			ce.addStructs(v.Index(i))
+		}
+	case reflect.Struct:
+		if !isStruct(v) {
+			return
+		}
+		fields := exportedFields(v)
+		exStruct := ExStruct{structName(v), make([]ExArg, len(fields))}
+		for i, f := range fields {
+			fv := v.FieldByIndex(f.Index)
+			ce.addStructs(fv)
+			exStruct.Fields[i] = ExArg{f.Name, fv.Kind(), valueArgType(fv)}
+		}
+		for _, s := range ce.Structs {
+			if s.Name == exStruct.Name {
+				return
+			}
+		}
+		ce.Structs = append(ce.Structs, exStruct)
+	}
+}
+
 var _ abstractor.API = &CodeExtractor{}
+var _ frontend.Committer = &CodeExtractor{}
+var _ frontend.Rangechecker = &CodeExtractor{}
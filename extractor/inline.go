@@ -0,0 +1,177 @@
+// This file contains the inlining pass, which replaces the calls to
+// gadgets with their bodies according to the inlining policy set with
+// the Option arguments and the abstractor.Inliner interface.
+package extractor
+
+import ()
+
+// hasInliner checks if any gadget has chosen to be inlined
+func (ce *CodeExtractor) hasInliner() bool {
+	for _, g := range ce.Gadgets {
+		if g.inline {
+			return true
+		}
+	}
+	return false
+}
+
+// shouldInline checks if the inlining policy applies to `gadget`
+func (ce *CodeExtractor) shouldInline(gadget ExGadget) bool {
+	switch {
+	case gadget.inline:
+		return true
+	case ce.Config.Inline[gadget.Name] || ce.Config.Inline[gadget.goType.Name()]:
+		return true
+	default:
+		return len(gadget.Code) < ce.Config.InlineBelow
+	}
+}
+
+// inlineGadgets replaces the calls to the gadgets to be inlined with
+// their code, both in `circuits` and in the other gadgets. Gadgets are
+// processed in order of definition, therefore the gadgets called by a
+// gadget are inlined in its code before deciding whether to inline it.
+// The gadgets which are inlined aren't exported, unless they are never
+// called (i.e. the gadget exported by GadgetToLean).
+func (ce *CodeExtractor) inlineGadgets(circuits []*ExCircuit) {
+	inlined := map[string]ExGadget{}
+	called := map[string]bool{}
+	gadgets := []ExGadget{}
+	for _, g := range ce.Gadgets {
+		g.Code, g.OutputsFlat = inlineCalls(g.Code, g.OutputsFlat, inlined, called)
+		if ce.shouldInline(g) {
+			inlined[g.Name] = g
+		}
+		gadgets = append(gadgets, g)
+	}
+	for _, c := range circuits {
+		c.Code, _ = inlineCalls(c.Code, nil, inlined, called)
+	}
+
+	ce.Gadgets = []ExGadget{}
+	for _, g := range gadgets {
+		if !called[g.Name] {
+			ce.Gadgets = append(ce.Gadgets, g)
+		}
+	}
+}
+
+// inlineCalls replaces the calls in `code` to the `inlined` gadgets with
+// their code, and records the gadgets in `called`. It returns the new
+// code and `outputs` with the Gate indices updated.
+func inlineCalls(code []App, outputs []Operand, inlined map[string]ExGadget, called map[string]bool) ([]App, []Operand) {
+	res := []App{}
+	gates := make([]Operand, len(code))
+	remap := func(index int) Operand {
+		return gates[index]
+	}
+	for i, app := range code {
+		args := make([]Operand, len(app.Args))
+		for j, arg := range app.Args {
+			args[j] = simplifyOperand(remapGates(arg, remap))
+		}
+		gadget, ok := ExGadget{}, false
+		if g, isGadget := app.Op.(*ExGadget); isGadget {
+			gadget, ok = inlined[g.Name]
+		}
+		if !ok {
+			gates[i] = Gate{len(res)}
+			res = append(res, App{app.Op, args})
+			continue
+		}
+
+		called[gadget.Name] = true
+		offset := len(res)
+		for _, bodyApp := range gadget.Code {
+			bodyArgs := make([]Operand, len(bodyApp.Args))
+			for j, arg := range bodyApp.Args {
+				bodyArgs[j] = inlineOperand(arg, args, offset)
+			}
+			res = append(res, App{bodyApp.Op, bodyArgs})
+		}
+		switch {
+		case len(gadget.OutputsFlat) == 0:
+			// The gate of gadgets without outputs isn't used
+		case !hasVectorOutputs(gadget):
+			gates[i] = inlineOperand(gadget.OutputsFlat[0], args, offset)
+		default:
+			projs := make([]Operand, len(gadget.OutputsFlat))
+			for j, output := range gadget.OutputsFlat {
+				projs[j] = inlineOperand(output, args, offset)
+			}
+			gates[i] = ProjArray{projs}
+		}
+	}
+	newOutputs := make([]Operand, len(outputs))
+	for i, output := range outputs {
+		newOutputs[i] = simplifyOperand(remapGates(output, remap))
+	}
+	return res, newOutputs
+}
+
+// inlineOperand returns the operand `op` of the body of a gadget as it's
+// used in the caller, where the gadget has been called with `args` and
+// its code starts at the Gate `offset`
+func inlineOperand(op Operand, args []Operand, offset int) Operand {
+	switch op := op.(type) {
+	case Input:
+		return args[op.Index]
+	case Gate:
+		return Gate{offset + op.Index}
+	case Proj:
+		return simplifyOperand(Proj{inlineOperand(op.Operand, args, offset), op.Index, op.Size})
+	case FieldProj:
+		return simplifyOperand(FieldProj{inlineOperand(op.Operand, args, offset), op.Name})
+	case ProjArray:
+		projs := make([]Operand, len(op.Projs))
+		for i, proj := range op.Projs {
+			projs[i] = inlineOperand(proj, args, offset)
+		}
+		return ProjArray{projs}
+	case ProjStruct:
+		values := make([]Operand, len(op.Values))
+		for i, value := range op.Values {
+			values[i] = inlineOperand(value, args, offset)
+		}
+		return ProjStruct{op.Fields, values}
+	default:
+		return op
+	}
+}
+
+// simplifyOperand replaces the elements of vectors and the fields of
+// structs built in place with their values (i.e. `vec![a, b][1]` is `b`)
+func simplifyOperand(op Operand) Operand {
+	switch op := op.(type) {
+	case Proj:
+		inner := simplifyOperand(op.Operand)
+		if array, ok := inner.(ProjArray); ok && op.Index < len(array.Projs) {
+			return array.Projs[op.Index]
+		}
+		return Proj{inner, op.Index, op.Size}
+	case FieldProj:
+		inner := simplifyOperand(op.Operand)
+		if value, ok := inner.(ProjStruct); ok {
+			for i, field := range value.Fields {
+				if field == op.Name {
+					return value.Values[i]
+				}
+			}
+		}
+		return FieldProj{inner, op.Name}
+	case ProjArray:
+		projs := make([]Operand, len(op.Projs))
+		for i, proj := range op.Projs {
+			projs[i] = simplifyOperand(proj)
+		}
+		return ProjArray{projs}
+	case ProjStruct:
+		values := make([]Operand, len(op.Values))
+		for i, value := range op.Values {
+			values[i] = simplifyOperand(value)
+		}
+		return ProjStruct{op.Fields, values}
+	default:
+		return op
+	}
+}
@@ -11,97 +11,88 @@ import (
 	"golang.org/x/exp/slices"
 )
 
-// CircuitToLeanWithName exports a `circuit` to Lean over a `field` with `namespace`
-// CircuitToLeanWithName and CircuitToLean aren't joined in a single function
-// CircuitToLean(circuit abstractor.Circuit, field ecc.ID, namespace ...string) because the long term view
-// is to add an optional parameter to support custom `set_option` directives in the header.
-func CircuitToLeanWithName(circuit frontend.Circuit, field ecc.ID, namespace string) (out string, err error) {
-	defer recoverError()
-
-	schema, err := getSchema(circuit)
-	if err != nil {
-		return "", err
+// Extract exports a `circuit` to Lean, writing the code to the output
+// set with WithOutput (os.Stdout by default). The namespace is the struct
+// name of `circuit` and the field is BN254, unless they're set with
+// WithNamespace and WithField. It returns the diagnostics reported during
+// the extraction, including the ones reported before an error.
+func Extract(circuit frontend.Circuit, opts ...Option) (diagnostics Diagnostics, err error) {
+	defer recoverError(&err)
+
+	config := newConfig(opts...)
+	if config.Namespace == "" {
+		config.Namespace = getStructName(circuit)
 	}
-
-	circuitInit(circuit, schema)
-
 	api := CodeExtractor{
 		Code:    []App{},
 		Gadgets: []ExGadget{},
-		FieldID: field,
+		FieldID: config.Field,
+		Config:  config,
 	}
+	defer func() {
+		diagnostics = api.diagnostics
+	}()
 
-	err = circuit.Define(&api)
+	schema, err := getSchema(circuit)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
+	api.circuitInit(circuit, schema)
 
-	extractorCircuit := ExCircuit{
-		Inputs:  getExArgs(circuit, schema.Fields),
-		Gadgets: api.Gadgets,
-		Code:    api.Code,
-		Field:   api.FieldID,
+	err = circuit.Define(&api)
+	if err != nil {
+		return nil, err
 	}
-	out = exportCircuit(extractorCircuit, namespace)
-	return out, nil
-}
-
-// CircuitToLean exports a `circuit` to Lean over a `field` with the namespace being the
-// struct name of `circuit`
-// When the namespace argument is not defined, it uses the name of the struct circuit
-func CircuitToLean(circuit frontend.Circuit, field ecc.ID) (string, error) {
-	name := getStructName(circuit)
-	return CircuitToLeanWithName(circuit, field, name)
-}
-
-// GadgetToLeanWithName exports a `gadget` to Lean over a `field` with `namespace`
-// Same notes written for CircuitToLeanWithName apply to GadgetToLeanWithName and GadgetToLean
-func GadgetToLeanWithName(gadget abstractor.GadgetDefinition, field ecc.ID, namespace string) (out string, err error) {
-	defer recoverError()
-
-	api := CodeExtractor{
-		Code:    []App{},
-		Gadgets: []ExGadget{},
-		FieldID: field,
+	err = api.runDeferred()
+	if err != nil {
+		return nil, err
 	}
-
-	api.DefineGadget(gadget)
-	gadgets := exportGadgets(api.Gadgets)
-	prelude := exportPrelude(namespace, api.FieldID.ScalarField())
-	footer := exportFooter(namespace)
-	return fmt.Sprintf("%s\n\n%s\n\n%s", prelude, gadgets, footer), nil
+	extractorCircuit := ExCircuit{
+		Name:   "circuit",
+		Inputs: getExArgs(circuit, schema.Fields),
+		Code:   api.Code,
+		Field:  api.FieldID,
+	}
+	api.runPasses([]*ExCircuit{&extractorCircuit})
+
+	api.addArgStructs(circuit, schema.Fields)
+	extractorCircuit.Gadgets = api.Gadgets
+	extractorCircuit.Structs = api.Structs
+	lw := leanWriter{w: config.Output}
+	exportCircuit(&lw, extractorCircuit, config.Namespace, config)
+	return nil, lw.err
 }
 
-// GadgetToLean exports a `gadget` to Lean over a `field`
-func GadgetToLean(gadget abstractor.GadgetDefinition, field ecc.ID) (string, error) {
-	name := getStructName(gadget)
-	return GadgetToLeanWithName(gadget, field, name)
+// ExtractGadget exports a `gadget` to Lean, writing the code to the output
+// set with WithOutput. The defaults and the diagnostics returned are the
+// same of Extract.
+func ExtractGadget(gadget abstractor.GadgetDefinition, opts ...Option) (Diagnostics, error) {
+	return ExtractGadgetList([]abstractor.GadgetDefinition{gadget}, opts...)
 }
 
-// ExtractCircuits is used to export a series of `circuits` to Lean over a `field` under `namespace`.
-func ExtractCircuits(namespace string, field ecc.ID, circuits ...frontend.Circuit) (out string, err error) {
-	defer recoverError()
+// ExtractList exports `circuits` to Lean, each as a definition named
+// after the circuit, writing the code to the output set with WithOutput.
+// Circuits with the same name are only exported once.
+func ExtractList(circuits []frontend.Circuit, opts ...Option) (diagnostics Diagnostics, err error) {
+	defer recoverError(&err)
 
+	config := newConfig(opts...)
 	api := CodeExtractor{
 		Code:    []App{},
 		Gadgets: []ExGadget{},
-		FieldID: field,
+		FieldID: config.Field,
+		Config:  config,
 	}
+	defer func() {
+		diagnostics = api.diagnostics
+	}()
 
-	var circuits_extracted []string
+	extracted := []*ExCircuit{}
 	var past_circuits []string
-
-	extractorCircuit := ExCircuit{
-		Inputs:  []ExArg{},
-		Gadgets: []ExGadget{},
-		Code:    []App{},
-		Field:   api.FieldID,
-	}
-
 	for _, circuit := range circuits {
 		schema, err := getSchema(circuit)
 		if err != nil {
-			return "", err
+			return nil, err
 		}
 		args := getExArgs(circuit, schema.Fields)
 		name := generateUniqueName(circuit, args)
@@ -110,46 +101,127 @@ func ExtractCircuits(namespace string, field ecc.ID, circuits ...frontend.Circui
 		}
 		past_circuits = append(past_circuits, name)
 
-		circuitInit(circuit, schema)
+		api.circuitInit(circuit, schema)
+		api.addArgStructs(circuit, schema.Fields)
 		err = circuit.Define(&api)
 		if err != nil {
-			return "", err
+			return nil, err
 		}
-
-		extractorCircuit.Inputs = args
-		extractorCircuit.Code = api.Code
-
-		circ := fmt.Sprintf("def %s %s: Prop :=\n%s", name, genArgs(extractorCircuit.Inputs), genCircuitBody(extractorCircuit))
-		circuits_extracted = append(circuits_extracted, circ)
+		err = api.runDeferred()
+		if err != nil {
+			return nil, err
+		}
+		extracted = append(extracted, &ExCircuit{Name: name, Inputs: args, Code: api.Code, Field: api.FieldID})
 
 		// Resetting elements for next circuit
-		extractorCircuit.Inputs = []ExArg{}
-		extractorCircuit.Code = []App{}
 		api.Code = []App{}
+		api.scope = scope{}
 	}
+	api.runPasses(extracted)
 
-	prelude := exportPrelude(namespace, extractorCircuit.Field.ScalarField())
-	gadgets := exportGadgets(api.Gadgets)
-	footer := exportFooter(namespace)
-	return fmt.Sprintf("%s\n\n%s\n\n%s\n\n%s", prelude, gadgets, strings.Join(circuits_extracted, "\n\n"), footer), nil
+	codes := make([][]App, len(extracted))
+	for i, c := range extracted {
+		codes[i] = c.Code
+	}
+	lw := leanWriter{w: config.Output}
+	lw.write(exportPrelude(config.Namespace, api.FieldID.ScalarField(), config), "\n\n", exportGatesExt(api.Gadgets, codes...))
+	exportGadgets(&lw, api.Structs, api.Gadgets)
+	lw.write("\n\n")
+	for i, c := range extracted {
+		if i > 0 {
+			lw.write("\n\n")
+		}
+		lw.write(fmt.Sprintf("%sdef %s %s: Prop :=\n%s", exportLoops(c.Code), c.Name, genArgs(c.Inputs), genCircuitBody(*c)))
+	}
+	lw.write("\n\n", exportFooter(config.Namespace))
+	return nil, lw.err
 }
 
-// ExtractGadgets is used to export a series of `gadgets` to Lean over a `field` under `namespace`.
-func ExtractGadgets(namespace string, field ecc.ID, gadgets ...abstractor.GadgetDefinition) (out string, err error) {
-	defer recoverError()
+// ExtractGadgetList exports `gadgets` to Lean, writing the code to the
+// output set with WithOutput. The namespace is the struct name of the
+// first gadget, unless it's set with WithNamespace.
+func ExtractGadgetList(gadgets []abstractor.GadgetDefinition, opts ...Option) (diagnostics Diagnostics, err error) {
+	defer recoverError(&err)
 
+	config := newConfig(opts...)
+	if config.Namespace == "" && len(gadgets) > 0 {
+		config.Namespace = getStructName(gadgets[0])
+	}
 	api := CodeExtractor{
 		Code:    []App{},
 		Gadgets: []ExGadget{},
-		FieldID: field,
+		FieldID: config.Field,
+		Config:  config,
 	}
+	defer func() {
+		diagnostics = api.diagnostics
+	}()
 
 	for _, gadget := range gadgets {
 		api.DefineGadget(gadget)
 	}
+	api.runPasses(nil)
+	lw := leanWriter{w: config.Output}
+	exportGadgetsFile(&lw, api.Structs, api.Gadgets, config.Namespace, api.FieldID.ScalarField(), config)
+	return nil, lw.err
+}
+
+// toString runs `extract` with the output set to a string, which is
+// returned. `opts` are applied after the options of the caller, and
+// the output set by the caller with WithOutput isn't used.
+func toString(extract func(...Option) (Diagnostics, error), callerOpts []Option, opts ...Option) (string, error) {
+	out := strings.Builder{}
+	opts = append(append(slices.Clone(callerOpts), opts...), WithOutput(&out))
+	if _, err := extract(opts...); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+// CircuitToLeanWithName exports a `circuit` to Lean over a `field` with `namespace`
+func CircuitToLeanWithName(circuit frontend.Circuit, field ecc.ID, namespace string, opts ...Option) (string, error) {
+	return CircuitToLean(circuit, field, append(slices.Clone(opts), WithNamespace(namespace))...)
+}
 
-	gadgets_string := exportGadgets(api.Gadgets)
-	prelude := exportPrelude(namespace, api.FieldID.ScalarField())
-	footer := exportFooter(namespace)
-	return fmt.Sprintf("%s\n\n%s\n\n%s", prelude, gadgets_string, footer), nil
+// CircuitToLean exports a `circuit` to Lean over a `field` with the namespace being the
+// struct name of `circuit`, unless it's set with WithNamespace. The code is returned
+// as a string, therefore WithOutput has no effect.
+func CircuitToLean(circuit frontend.Circuit, field ecc.ID, opts ...Option) (string, error) {
+	extract := func(opts ...Option) (Diagnostics, error) {
+		return Extract(circuit, opts...)
+	}
+	return toString(extract, opts, WithField(field))
+}
+
+// GadgetToLeanWithName exports a `gadget` to Lean over a `field` with `namespace`
+func GadgetToLeanWithName(gadget abstractor.GadgetDefinition, field ecc.ID, namespace string, opts ...Option) (string, error) {
+	return GadgetToLean(gadget, field, append(slices.Clone(opts), WithNamespace(namespace))...)
+}
+
+// GadgetToLean exports a `gadget` to Lean over a `field` with the namespace being the
+// struct name of `gadget`, unless it's set with WithNamespace. The code is returned
+// as a string, therefore WithOutput has no effect.
+func GadgetToLean(gadget abstractor.GadgetDefinition, field ecc.ID, opts ...Option) (string, error) {
+	extract := func(opts ...Option) (Diagnostics, error) {
+		return ExtractGadget(gadget, opts...)
+	}
+	return toString(extract, opts, WithField(field))
+}
+
+// ExtractCircuits is used to export a series of `circuits` to Lean over a `field` under `namespace`.
+// Use ExtractList to set other options.
+func ExtractCircuits(namespace string, field ecc.ID, circuits ...frontend.Circuit) (string, error) {
+	extract := func(opts ...Option) (Diagnostics, error) {
+		return ExtractList(circuits, opts...)
+	}
+	return toString(extract, nil, WithField(field), WithNamespace(namespace))
+}
+
+// ExtractGadgets is used to export a series of `gadgets` to Lean over a `field` under `namespace`.
+// Use ExtractGadgetList to set other options.
+func ExtractGadgets(namespace string, field ecc.ID, gadgets ...abstractor.GadgetDefinition) (string, error) {
+	extract := func(opts ...Option) (Diagnostics, error) {
+		return ExtractGadgetList(gadgets, opts...)
+	}
+	return toString(extract, nil, WithField(field), WithNamespace(namespace))
 }
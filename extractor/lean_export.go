@@ -2,6 +2,7 @@ package extractor
 
 import (
 	"fmt"
+	"io"
 	"math/big"
 	"reflect"
 	"regexp"
@@ -9,6 +10,7 @@ import (
 
 	"github.com/consensys/gnark/frontend"
 	"github.com/consensys/gnark/frontend/schema"
+	"golang.org/x/exp/slices"
 )
 
 // isWhitespacePresent checks there are no whitespaces in the middle
@@ -19,24 +21,37 @@ func isWhitespacePresent(input string) bool {
 }
 
 // exportPrelude generates the string to put at the beginning of the
-// autogenerated Lean4 code. It includes the relevant `provenZK` library
-// import.
-func exportPrelude(name string, order *big.Int) string {
+// autogenerated Lean4 code. It includes the imports and `set_option`
+// directives of the target of `config`, followed by the ones of `config`.
+func exportPrelude(name string, order *big.Int, config Config) string {
 	trimmedName := strings.TrimSpace(name)
 	if isWhitespacePresent(trimmedName) {
-		panic("Whitespace isn't allowed in namespace tag")
+		fail(ErrInvalidNamespace, name, "Whitespace isn't allowed in namespace tag")
 	}
-	s := fmt.Sprintf(`import ProvenZk.Gates
-import ProvenZk.Ext.Vector
-
-set_option linter.unusedVariables false
-
-namespace %s
+	if config.Target.Gates == "" {
+		fail(ErrInvalidArgument, config.Target, "Target without Gates variant")
+	}
+	imports := []string{}
+	for _, i := range append(slices.Clone(config.Target.Imports), config.Imports...) {
+		imports = append(imports, fmt.Sprintf("import %s", i))
+	}
+	options := []string{}
+	for _, o := range append(slices.Clone(config.Target.SetOptions), config.SetOptions...) {
+		options = append(options, fmt.Sprintf("set_option %s", o))
+	}
+	header := []string{}
+	for _, lines := range [][]string{imports, options} {
+		if len(lines) > 0 {
+			header = append(header, strings.Join(lines, "\n"))
+		}
+	}
+	header = append(header, fmt.Sprintf("namespace %s", trimmedName))
+	s := fmt.Sprintf(`%s
 
 def Order : ℕ := 0x%s
 variable [Fact (Nat.Prime Order)]
 abbrev F := ZMod Order
-abbrev Gates := %s Order`, trimmedName, order.Text(16), "GatesGnark9")
+abbrev Gates := %s Order`, strings.Join(header, "\n\n"), order.Text(16), config.Target.Gates)
 
 	return s
 }
@@ -47,7 +62,7 @@ abbrev Gates := %s Order`, trimmedName, order.Text(16), "GatesGnark9")
 func exportFooter(name string) string {
 	trimmedName := strings.TrimSpace(name)
 	if isWhitespacePresent(trimmedName) {
-		panic("Whitespace isn't allowed in namespace tag")
+		fail(ErrInvalidNamespace, name, "Whitespace isn't allowed in namespace tag")
 	}
 	s := fmt.Sprintf(`end %s`, trimmedName)
 	return s
@@ -56,50 +71,195 @@ func exportFooter(name string) string {
 // genKTypeSignature generates the type signature of the `k`
 // argument of exported gadgets.
 func genKTypeSignature(output reflect.Value) string {
-	if output.Kind() != reflect.Slice {
-		return ""
+	return genSizedKTypeSignature(output, nil)
+}
+
+// genSizedKTypeSignature is genKTypeSignature with the sizes of the
+// nested vectors, outermost first, replaced by `sizes` if present.
+func genSizedKTypeSignature(output reflect.Value, sizes []string) string {
+	if output.Kind() == reflect.Interface && !output.IsNil() {
+		output = output.Elem()
+	}
+	switch {
+	case output.Kind() == reflect.Slice || output.Kind() == reflect.Array:
+		size := fmt.Sprintf("%d", output.Len())
+		if len(sizes) > 0 {
+			size = sizes[0]
+			sizes = sizes[1:]
+		}
+		if output.Len() == 0 {
+			return fmt.Sprintf("Vector F %s", size)
+		}
+		innerType := genSizedKTypeSignature(output.Index(0), sizes)
+		if output.Index(0).Kind() == reflect.Slice || output.Index(0).Kind() == reflect.Array {
+			innerType = fmt.Sprintf("(%s)", innerType)
+		}
+		return fmt.Sprintf("Vector %s %s", innerType, size)
+	case isStruct(output):
+		return structName(output)
+	default:
+		return "F"
 	}
-	if output.Index(0).Kind() == reflect.Slice {
-		innerType := genKTypeSignature(output.Index(0))
-		return fmt.Sprintf("Vector (%s) %d", innerType, output.Len())
+}
+
+// gadgetOutputType returns the Lean type of the outputs of `gadget`
+func gadgetOutputType(gadget ExGadget) string {
+	if gadget.OutputType != "" {
+		return gadget.OutputType
 	}
-	return fmt.Sprintf("Vector F %d", output.Len())
+	return genKTypeSignature(reflect.ValueOf(gadget.Outputs))
 }
 
-// exportGadget generates the `gadget` function in Lean
+// exportGadget generates the `gadget` function in Lean, preceded
+// by the definitions of the loops recovered in its body
 func exportGadget(gadget ExGadget) string {
 	kArgs := ""
-	if len(gadget.OutputsFlat) == 1 {
-		kArgs = "(k: F -> Prop)"
-	} else if len(gadget.OutputsFlat) > 1 {
-		outputType := genKTypeSignature(reflect.ValueOf(gadget.Outputs))
-		kArgs = fmt.Sprintf("(k: %s -> Prop)", outputType)
+	if len(gadget.OutputsFlat) > 0 {
+		kArgs = fmt.Sprintf("(k: %s -> Prop)", gadgetOutputType(gadget))
 	}
 	inAssignment := gadget.Args
+	args := genArgs(inAssignment)
+	if len(gadget.Params) > 0 {
+		args = fmt.Sprintf("{%s : ℕ} %s", strings.Join(gadget.Params, " "), args)
+	}
 
-	return fmt.Sprintf("def %s %s %s: Prop :=\n%s", gadget.Name, genArgs(inAssignment), kArgs, genGadgetBody(inAssignment, gadget))
+	return fmt.Sprintf("%sdef %s %s %s: Prop :=\n%s", exportLoops(gadget.Code), gadget.Name, args, kArgs, genGadgetBody(inAssignment, gadget))
 }
 
-func exportGadgets(exGadgets []ExGadget) string {
-	gadgets := make([]string, len(exGadgets))
+// exportLoops generates the recursive definitions of the loops in `code`
+func exportLoops(code []App) string {
+	loops := ""
+	for _, app := range code {
+		if loop, ok := app.Op.(*ExLoop); ok {
+			loops += exportLoop(loop) + "\n\n"
+		}
+	}
+	return loops
+}
+
+// exportLoop generates the recursive definition of `loop`. Each call
+// consumes the heads of the lists and passes the values carried to the
+// next iteration to the recursive call. When the lists are exhausted,
+// the carried values are passed to `k`.
+func exportLoop(loop *ExLoop) string {
+	args := make([]string, len(loop.Args))
+	for i, arg := range loop.Args {
+		args[i] = fmt.Sprintf("(%s: %s)", arg.Name, loop.Types[i])
+	}
+	lists := make([]string, len(loop.Lists))
+	heads := make([]string, len(loop.Lists))
+	ends := make([]string, len(loop.Lists))
+	for i := range loop.Lists {
+		lists[i] = loop.Args[i].Name
+		heads[i] = fmt.Sprintf("%s :: %s", loop.Elems[i], lists[i])
+		ends[i] = "_"
+	}
+	carries := make([]string, loop.Carries)
+	for i := range carries {
+		carries[i] = loop.Args[len(loop.Lists)+i].Name
+	}
+	params := []string{}
+	for _, arg := range loop.Args[len(loop.Lists)+loop.Carries:] {
+		params = append(params, arg.Name)
+	}
+
+	kArgs := ""
+	result := "True"
+	switch loop.Carries {
+	case 0:
+	case 1:
+		kArgs = "(k: F -> Prop)"
+		result = fmt.Sprintf("k %s", carries[0])
+	default:
+		kArgs = fmt.Sprintf("(k: Vector F %d -> Prop)", loop.Carries)
+		result = fmt.Sprintf("k vec![%s]", strings.Join(carries, ", "))
+	}
+
+	inAssignment := append([]ExArg{}, loop.Args...)
+	for _, elem := range loop.Elems {
+		inAssignment = append(inAssignment, ExArg{Name: elem})
+	}
+	gateVars := assignGateVars(loop.Code, loop.Next...)
+	body := ""
+	for i, app := range loop.Code {
+		body += "    " + genLine(app, gateVars[i], inAssignment, gateVars)
+	}
+	recursion := append(append(append([]string{loop.Name}, lists...), operandExprs(loop.Next, inAssignment, gateVars)...), params...)
+	if loop.Carries > 0 {
+		recursion = append(recursion, "k")
+	}
+
+	return fmt.Sprintf("def %s %s %s: Prop :=\n    match %s with\n    | %s =>\n%s        %s\n    | %s => %s",
+		loop.Name, strings.Join(args, " "), kArgs,
+		strings.Join(lists, ", "), strings.Join(heads, ", "), body, strings.Join(recursion, " "),
+		strings.Join(ends, ", "), result)
+}
+
+// exportStructs generates the Lean structures of `exStructs`
+func exportStructs(exStructs []ExStruct) []string {
+	structs := make([]string, len(exStructs))
+	for i, exStruct := range exStructs {
+		fields := make([]string, len(exStruct.Fields))
+		for j, f := range exStruct.Fields {
+			fields[j] = fmt.Sprintf("    %s : %s", f.Name, genArgType(f))
+		}
+		structs[i] = fmt.Sprintf("structure %s where\n%s", exStruct.Name, strings.Join(fields, "\n"))
+	}
+	return structs
+}
+
+// leanWriter writes the Lean code to an io.Writer one definition at a time.
+// It keeps the first error of the writer and skips the following writes.
+type leanWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (lw *leanWriter) write(s ...string) {
+	for _, str := range s {
+		if lw.err == nil {
+			_, lw.err = io.WriteString(lw.w, str)
+		}
+	}
+}
+
+// exportGadgets writes the `gadgets` functions in Lean, preceded
+// by the structures they use
+func exportGadgets(lw *leanWriter, exStructs []ExStruct, exGadgets []ExGadget) {
+	defs := exportStructs(exStructs)
+	for i, def := range defs {
+		if i > 0 {
+			lw.write("\n\n")
+		}
+		lw.write(def)
+	}
 	for i, gadget := range exGadgets {
-		gadgets[i] = exportGadget(gadget)
+		if i > 0 || len(defs) > 0 {
+			lw.write("\n\n")
+		}
+		lw.write(exportGadget(gadget))
 	}
-	return strings.Join(gadgets, "\n\n")
 }
 
-// exportCircuit generates the `circuit` function in Lean
-func exportCircuit(circuit ExCircuit, name string) string {
-	gadgets := exportGadgets(circuit.Gadgets)
-	circ := fmt.Sprintf("def circuit %s: Prop :=\n%s", genArgs(circuit.Inputs), genCircuitBody(circuit))
-	prelude := exportPrelude(name, circuit.Field.ScalarField())
-	footer := exportFooter(name)
-	return fmt.Sprintf("%s\n\n%s\n\n%s\n\n%s", prelude, gadgets, circ, footer)
+// exportGadgetsFile writes the `gadgets` functions in Lean between
+// the prelude and the footer of `name`
+func exportGadgetsFile(lw *leanWriter, exStructs []ExStruct, exGadgets []ExGadget, name string, order *big.Int, config Config) {
+	lw.write(exportPrelude(name, order, config), "\n\n", exportGatesExt(exGadgets))
+	exportGadgets(lw, exStructs, exGadgets)
+	lw.write("\n\n", exportFooter(name))
+}
+
+// exportCircuit writes the `circuit` function in Lean
+func exportCircuit(lw *leanWriter, circuit ExCircuit, name string, config Config) {
+	lw.write(exportPrelude(name, circuit.Field.ScalarField(), config), "\n\n", exportGatesExt(circuit.Gadgets, circuit.Code))
+	exportGadgets(lw, circuit.Structs, circuit.Gadgets)
+	circ := fmt.Sprintf("%sdef circuit %s: Prop :=\n%s", exportLoops(circuit.Code), genArgs(circuit.Inputs), genCircuitBody(circuit))
+	lw.write("\n\n", circ, "\n\n", exportFooter(name))
 }
 
 // circuitInit takes struct and a schema to populate all the
 // circuit/gagdget fields with Operand.
-func circuitInit(class any, schema *schema.Schema) {
+func (ce *CodeExtractor) circuitInit(class any, schema *schema.Schema) {
 	// https://stackoverflow.com/a/49704408
 	// https://stackoverflow.com/a/14162161
 	// https://stackoverflow.com/a/63422049
@@ -120,42 +280,48 @@ func circuitInit(class any, schema *schema.Schema) {
 	tmp_c := reflect.ValueOf(&class).Elem().Elem()
 	tmp := reflect.New(tmp_c.Type()).Elem()
 	tmp.Set(tmp_c)
-	for j, f := range schema.Fields {
-		field_name := f.Name
-		field := v.FieldByName(field_name)
+	// Ragged slices are split in multiple fields, therefore
+	// the index of Input is the index in the list of exField
+	for j, f := range getExFields(tmp.Elem(), schema.Fields) {
+		field := f.Value
 		field_type := field.Type()
 
 		// Can't assign an array to another array, therefore
 		// initialise each element in the array
 
 		if field_type.Kind() == reflect.Array {
-			arrayInit(f, tmp.Elem().FieldByName(field_name), Input{j})
+			arrayInit(f.Field, field, Input{j})
 		} else if field_type.Kind() == reflect.Slice {
 			// Recreate a zeroed array to remove overlapping pointers if input
 			// arguments are duplicated (i.e. `api.Call(SliceGadget{circuit.Path, circuit.Path})`)
-			arrayZero(tmp.Elem().FieldByName(field_name))
-			arrayInit(f, tmp.Elem().FieldByName(field_name), Input{j})
+			arrayZero(field)
+			arrayInit(f.Field, field, Input{j})
+		} else if field_type.Kind() == reflect.Struct {
+			structInit(f.Field, field, Input{j})
 		} else if field_type.Kind() == reflect.Interface {
 			init := Input{j}
 			value := reflect.ValueOf(init)
 
-			tmp.Elem().FieldByName(field_name).Set(value)
+			field.Set(value)
 		} else {
-			fmt.Printf("Skipped type %s\n", field_type.Kind())
+			ce.warn(f.Name, "Skipped field of type %s", field_type.Kind())
 		}
 	}
 }
 
-func circuitArgs(field schema.Field) ExArgType {
-	// Handling only subfields which are nested arrays
-	switch len(field.SubFields) {
-	case 1:
-		subType := circuitArgs(field.SubFields[0])
-		return ExArgType{field.ArraySize, &subType}
-	case 0:
-		return ExArgType{field.ArraySize, nil}
+// circuitArgs generates the ExArgType of `field` with value `v`.
+// The value is needed to generate the names of the structs.
+func circuitArgs(field schema.Field, v reflect.Value) ExArgType {
+	switch {
+	case field.Type == schema.Struct:
+		return ExArgType{Struct: structName(v)}
+	case len(field.SubFields) == 0:
+		return ExArgType{Size: field.ArraySize}
+	case field.SubFields[0].Type == schema.Struct:
+		return ExArgType{Size: field.ArraySize, Struct: structName(v.Index(0))}
 	default:
-		panic("Only nested arrays supported in SubFields")
+		subType := circuitArgs(field.SubFields[0], v.Index(0))
+		return ExArgType{Size: field.ArraySize, Type: &subType}
 	}
 }
 
@@ -163,9 +329,8 @@ func circuitArgs(field schema.Field) ExArgType {
 // list of `Field`. It is used in the Circuit to Lean functions
 func getExArgs(circuit any, fields []schema.Field) []ExArg {
 	args := []ExArg{}
-	for _, f := range fields {
-		kind := kindOfField(circuit, f.Name)
-		arg := ExArg{f.Name, kind, circuitArgs(f)}
+	for _, f := range getExFields(reflect.ValueOf(circuit).Elem(), fields) {
+		arg := ExArg{f.Name, f.Value.Kind(), circuitArgs(f.Field, f.Value)}
 		args = append(args, arg)
 	}
 	return args
@@ -178,21 +343,37 @@ func getSchema(circuit any) (*schema.Schema, error) {
 }
 
 func genNestedArrays(a ExArgType) string {
+	size := fmt.Sprintf("%d", a.Size)
+	if a.Param != "" {
+		size = a.Param
+	}
 	if a.Type != nil {
-		return fmt.Sprintf("Vector (%s) %d", genNestedArrays(*a.Type), a.Size)
+		return fmt.Sprintf("Vector (%s) %s", genNestedArrays(*a.Type), size)
+	}
+	if a.Struct != "" {
+		return fmt.Sprintf("Vector %s %s", a.Struct, size)
+	}
+	return fmt.Sprintf("Vector F %s", size)
+}
+
+// genArgType generates the Lean type of the argument `in`
+func genArgType(in ExArg) string {
+	switch in.Kind {
+	case reflect.Array, reflect.Slice:
+		return genNestedArrays(in.Type)
+	case reflect.Struct:
+		return in.Type.Struct
+	case reflect.Int:
+		return "ℕ"
+	default:
+		return "F"
 	}
-	return fmt.Sprintf("Vector F %d", a.Size)
 }
 
 func genArgs(inAssignment []ExArg) string {
 	args := make([]string, len(inAssignment))
 	for i, in := range inAssignment {
-		switch in.Kind {
-		case reflect.Array, reflect.Slice:
-			args[i] = fmt.Sprintf("(%s: %s)", in.Name, genNestedArrays(in.Type))
-		default:
-			args[i] = fmt.Sprintf("(%s: F)", in.Name)
-		}
+		args[i] = fmt.Sprintf("(%s: %s)", in.Name, genArgType(in))
 	}
 	return strings.Join(args, " ")
 }
@@ -201,6 +382,14 @@ func extractGateVars(arg Operand) []Operand {
 	switch arg.(type) {
 	case Proj:
 		return extractGateVars(arg.(Proj).Operand)
+	case FieldProj:
+		return extractGateVars(arg.(FieldProj).Operand)
+	case ProjStruct:
+		res := []Operand{}
+		for _, value := range arg.(ProjStruct).Values {
+			res = append(res, extractGateVars(value)...)
+		}
+		return res
 	case ProjArray:
 		res := []Operand{}
 		for i := range arg.(ProjArray).Projs {
@@ -257,6 +446,79 @@ func genGadgetCall(gateVar string, inAssignment []ExArg, gateVars []string, gadg
 	return fmt.Sprintf("    %s %s %s\n", name, strings.Join(operands, " "), binder)
 }
 
+// genLoopCall generates the call to a recovered loop. The first
+// arguments are the vectors iterated by the loop, which are passed
+// as the lists of the elements used by the iterations.
+func genLoopCall(gateVar string, inAssignment []ExArg, gateVars []string, loop *ExLoop, args []Operand) string {
+	operands := operandExprs(args, inAssignment, gateVars)
+	for i, list := range loop.Lists {
+		operands[i] = fmt.Sprintf("%s.toList", operands[i])
+		if list.Start > 0 {
+			operands[i] = fmt.Sprintf("(%s.drop %d)", operands[i], list.Start)
+		}
+		if list.Start+list.Count < list.Size {
+			operands[i] = fmt.Sprintf("(%s.take %d)", operands[i], list.Count)
+		}
+	}
+	binder := "∧"
+	if loop.Carries > 0 {
+		binder = "fun _ =>"
+		if gateVar != "" {
+			binder = fmt.Sprintf("fun %s =>", gateVar)
+		}
+	}
+	return fmt.Sprintf("    %s %s %s\n", loop.Name, strings.Join(operands, " "), binder)
+}
+
+// gatesExt are the gates which aren't fields of the Gates structure of
+// ProvenZK. They are defined in the GatesExt namespace of the exported
+// file, only if the code uses them.
+var gatesExt = map[Op]struct {
+	name string
+	def  string
+}{
+	OpCommit:        {"commit", "axiom commit {n : ℕ} : Vector F n -> F"},
+	OpRangeCheck:    {"range_check", "def range_check (a : F) (n : ℕ) : Prop := a.val < 2^n"},
+	OpAssertIsCrumb: {"is_crumb", "def is_crumb (a : F) : Prop := a.val < 4"},
+}
+
+// usedGatesExt appends to `used` the ops of gatesExt found in `code`,
+// including the bodies of the loops.
+func usedGatesExt(used []Op, code []App) []Op {
+	for _, app := range code {
+		if loop, ok := app.Op.(*ExLoop); ok {
+			used = usedGatesExt(used, loop.Code)
+		} else if _, ok := gatesExt[app.Op]; ok && !slices.Contains(used, app.Op) {
+			used = append(used, app.Op)
+		}
+	}
+	return used
+}
+
+// exportGatesExt generates the GatesExt namespace with the definitions
+// of the gates of gatesExt used by `gadgets` or `codes`. It returns
+// an empty string if none is used.
+func exportGatesExt(gadgets []ExGadget, codes ...[]App) string {
+	used := []Op{}
+	for _, gadget := range gadgets {
+		used = usedGatesExt(used, gadget.Code)
+	}
+	for _, code := range codes {
+		used = usedGatesExt(used, code)
+	}
+	if len(used) == 0 {
+		return ""
+	}
+	defs := []string{"namespace GatesExt"}
+	for _, op := range []Op{OpCommit, OpRangeCheck, OpAssertIsCrumb} {
+		if slices.Contains(used, op) {
+			defs = append(defs, gatesExt[op].def)
+		}
+	}
+	defs = append(defs, "end GatesExt")
+	return strings.Join(defs, "\n") + "\n\n"
+}
+
 func genGateOp(op Op) string {
 	name := "unknown"
 	switch op {
@@ -294,7 +556,7 @@ func genGateOp(op Op) string {
 		name = "eq"
 	case OpAssertNotEq:
 		name = "ne"
-	case OpAssertIsBool:
+	case OpAssertIsBool, OpMarkBoolean:
 		name = "is_bool"
 	case OpAssertLessEqual:
 		name = "le"
@@ -303,6 +565,9 @@ func genGateOp(op Op) string {
 	case OpToBinary:
 		name = "to_binary"
 	}
+	if ext, ok := gatesExt[op]; ok {
+		return fmt.Sprintf("GatesExt.%s", ext.name)
+	}
 
 	return fmt.Sprintf("Gates.%s", name)
 }
@@ -336,21 +601,30 @@ func genGenericGate(op Op, operands []string) string {
 	return fmt.Sprintf("    %s %s ∧\n", genGateOp(op), strings.Join(operands, " "))
 }
 
+// genComment generates a Lean comment made of `operands`.
+func genComment(operands []string) string {
+	return fmt.Sprintf("    -- %s\n", strings.Join(operands, " "))
+}
+
 func genOpCall(gateVar string, inAssignment []ExArg, gateVars []string, op Op, args []Operand) string {
+	if op == OpPrintln {
+		return genComment(operandExprs(args, inAssignment, gateVars))
+	}
+
 	// functional is set to true when the op returns a value
 	functional := false
 	callback := false
 	switch op {
 	case OpDivUnchecked, OpDiv, OpInverse, OpXor, OpOr, OpAnd, OpSelect, OpLookup, OpCmp, OpIsZero, OpToBinary, OpFromBinary:
 		callback = true
-	case OpAdd, OpMulAcc, OpNegative, OpSub, OpMul:
+	case OpAdd, OpMulAcc, OpNegative, OpSub, OpMul, OpCommit:
 		functional = true
 	}
 
 	operands := operandExprs(args, inAssignment, gateVars)
-	if op == OpFromBinary {
-		// OpFromBinary takes only one argument which is represented as list of Proj. For this reason we can
-		// safely wrap it in a ProjArray and call operandExpr directly.
+	if op == OpFromBinary || op == OpCommit {
+		// OpFromBinary and OpCommit take only one argument which is represented as list of Proj. For this
+		// reason we can safely wrap it in a ProjArray and call operandExpr directly.
 		projArray := ProjArray{args}
 		operands = []string{operandExpr(projArray, inAssignment, gateVars)}
 	}
@@ -379,10 +653,43 @@ func genOpCall(gateVar string, inAssignment []ExArg, gateVars []string, op Op, a
 	}
 }
 
+// genHint generates the existential quantifier for the outputs of a hint.
+// The arguments of the hint aren't exported because they don't constrain
+// the outputs.
+func genHint(gateVar string, hint ExHint) string {
+	gateName := getGateName(gateVar, false)
+	tag := ""
+	if hint.Name != "" {
+		tag = fmt.Sprintf(" -- hint %s", hint.Name)
+	}
+	return fmt.Sprintf("    ∃%s: Vector F %d,%s\n", gateName, hint.NbOutputs, tag)
+}
+
+// genRemoved generates the comment reporting the removal of a gate
+func genRemoved(gateVar string, inAssignment []ExArg, gateVars []string, removed ExRemoved, args []Operand) string {
+	if hint, ok := removed.Op.(ExHint); ok {
+		if hint.Name == "" {
+			return genComment([]string{"removed unused hint"})
+		}
+		return genComment([]string{"removed unused hint", hint.Name})
+	}
+	operands := operandExprs(args, inAssignment, gateVars)
+	if removed.Op == OpCommit {
+		operands = []string{operandExpr(ProjArray{args}, inAssignment, gateVars)}
+	}
+	return genComment(append([]string{"removed", getGateName(gateVar, false), "=", genGateOp(removed.Op)}, operands...))
+}
+
 func genLine(app App, gateVar string, inAssignment []ExArg, gateVars []string) string {
 	switch app.Op.(type) {
 	case *ExGadget:
 		return genGadgetCall(gateVar, inAssignment, gateVars, app.Op.(*ExGadget), app.Args)
+	case *ExLoop:
+		return genLoopCall(gateVar, inAssignment, gateVars, app.Op.(*ExLoop), app.Args)
+	case ExHint:
+		return genHint(gateVar, app.Op.(ExHint))
+	case ExRemoved:
+		return genRemoved(gateVar, inAssignment, gateVars, app.Op.(ExRemoved), app.Args)
 	case Op:
 		return genOpCall(gateVar, inAssignment, gateVars, app.Op.(Op), app.Args)
 	}
@@ -396,12 +703,12 @@ func genGadgetBody(inAssignment []ExArg, gadget ExGadget) string {
 		lines[i] = genLine(app, gateVars[i], inAssignment, gateVars)
 	}
 
-	switch len(gadget.OutputsFlat) {
-	case 0:
+	switch {
+	case len(gadget.OutputsFlat) == 0:
 		lastLine := "    True"
 		return strings.Join(append(lines, lastLine), "")
-	case 1:
-		// The case statement ensures there is index 0 (and only 0)
+	case !hasVectorOutputs(gadget):
+		// Outputs which aren't slices or arrays are flattened to a single element
 		result := operandExpr(gadget.OutputsFlat[0], inAssignment, gateVars)
 		lastLine := fmt.Sprintf("    k %s", result)
 		return strings.Join(append(lines, lastLine), "")
@@ -432,6 +739,8 @@ func getArgIndex(operand ProjArray) int {
 			return op.Index
 		case Proj:
 			return getArgIndex(ProjArray{[]Operand{op}})
+		case FieldProj:
+			return getArgIndex(ProjArray{[]Operand{Proj{Operand: op.Operand}}})
 		default:
 			return -1
 		}
@@ -459,11 +768,12 @@ func checkVector(operand ProjArray, argIdx int) (bool, Operand) {
 	// Check indices are in ascending order
 	// on the same argIdx
 	for _, op := range operand.Projs[1:] {
-		if lastIndex != op.(Proj).Index-1 {
+		proj, ok := op.(Proj)
+		if !ok || lastIndex != proj.Index-1 {
 			return false, operand
 		}
 		lastIndex += 1
-		if firstOperand != op.(Proj).Operand {
+		if firstOperand != proj.Operand {
 			return false, operand
 		}
 	}
@@ -508,11 +818,13 @@ func checkDimensions(operand ProjArray, length []int, argIndex Operand, pastIndi
 	for i, p := range operand.Projs {
 		if len(length[1:]) >= 1 {
 			past := append(pastIndices, i)
-			if !checkDimensions(p.(ProjArray), length[1:], argIndex, past...) {
+			projArray, ok := p.(ProjArray)
+			if !ok || !checkDimensions(projArray, length[1:], argIndex, past...) {
 				return false
 			}
 		} else {
-			if !expectedOperand(p.(Proj), argIndex, append(pastIndices, i)) {
+			proj, ok := p.(Proj)
+			if !ok || !expectedOperand(proj, argIndex, append(pastIndices, i)) {
 				return false
 			}
 		}
@@ -526,8 +838,8 @@ func getFirstOperand(operand ProjArray) Operand {
 	} else if reflect.TypeOf(operand.Projs[0]) == reflect.TypeOf(Proj{}) {
 		return operand.Projs[0].(Proj)
 	} else {
-		fmt.Printf("getFirstOperand %+v\n", operand)
-		panic("Error in getFirstOperand.")
+		fail(ErrUnsupportedType, operand, "Error in getFirstOperand.")
+		return nil
 	}
 }
 
@@ -594,13 +906,25 @@ func operandExpr(operand Operand, inAssignment []ExArg, gateVars []string) strin
 		opArray := operandExprs(operand.(ProjArray).Projs, inAssignment, gateVars)
 		opArray = []string{strings.Join(opArray, ", ")}
 		return fmt.Sprintf("vec!%s", opArray)
+	case FieldProj:
+		return fmt.Sprintf("%s.%s", operandExpr(operand.(FieldProj).Operand, inAssignment, gateVars), operand.(FieldProj).Name)
+	case ProjStruct:
+		values := operandExprs(operand.(ProjStruct).Values, inAssignment, gateVars)
+		fields := make([]string, len(values))
+		for i, value := range values {
+			fields[i] = fmt.Sprintf("%s := %s", operand.(ProjStruct).Fields[i], value)
+		}
+		return fmt.Sprintf("{ %s }", strings.Join(fields, ", "))
 	case Const:
 		return fmt.Sprintf("(%s:F)", operand.(Const).Value.Text(10))
 	case Integer:
 		return operand.(Integer).Value.Text(10)
+	case Text:
+		// Newlines would end the Lean comment
+		return strings.ReplaceAll(operand.(Text).Value, "\n", " ")
 	default:
-		fmt.Printf("Type %T\n", operand)
-		panic("not yet supported")
+		fail(ErrUnsupportedType, operand, "Operand of type %T not yet supported", operand)
+		return ""
 	}
 }
 
@@ -0,0 +1,582 @@
+// This file contains the loop recovery pass, which replaces the
+// blocks of code repeated by Go `for` loops with calls to recursive
+// Lean definitions iterating over the lists of the vector elements.
+package extractor
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+)
+
+// ExLoop is the Op recorded in place of a recovered loop. Each
+// iteration takes the heads of Lists and the values carried from the
+// previous iteration. The outputs of the loop are the carried values
+// after the last iteration.
+type ExLoop struct {
+	Name string
+	// Args are the lists, the carried values and the arguments which
+	// are the same in all the iterations, in this order
+	Args  []ExArg
+	Types []string
+	// Elems are the names of the heads of the lists
+	Elems   []string
+	Lists   []loopList
+	Carries int
+	// Code is the body of the loop. Input refers to Args followed by Elems
+	Code []App
+	// Next are the values carried to the next iteration
+	Next []Operand
+}
+
+func (_ *ExLoop) isOp() {}
+
+// loopList is a vector iterated by a loop from the element Start.
+// Count is the number of iterations and Size is the length of Vector.
+type loopList struct {
+	Vector Operand
+	Start  int
+	Count  int
+	Size   int
+}
+
+// recoverLoops recovers the loops in the gadgets and in `circuits`
+func (ce *CodeExtractor) recoverLoops(circuits []*ExCircuit) {
+	for _, c := range circuits {
+		c.Code, _ = ce.recoverLoopsIn("", c.Name, c.Code, c.Inputs, nil)
+	}
+	for i, g := range ce.Gadgets {
+		ce.Gadgets[i].Code, ce.Gadgets[i].OutputsFlat = ce.recoverLoopsIn(g.Name, g.Name, g.Code, g.Args, g.OutputsFlat)
+	}
+}
+
+// recoverLoopsIn is recoverLoops for the code of `gadget`, reporting
+// the loops recovered
+func (ce *CodeExtractor) recoverLoopsIn(gadget string, owner string, code []App, args []ExArg, outputs []Operand) ([]App, []Operand) {
+	code, outputs = recoverLoops(owner, code, args, outputs)
+	for _, app := range code {
+		if loop, ok := app.Op.(*ExLoop); ok {
+			ce.report(SeverityInfo, gadget, "", "recovered loop %s of %d iterations", loop.Name, loop.Lists[0].Count)
+		}
+	}
+	return code, outputs
+}
+
+// recoverLoops replaces the loops found in `code` with calls to ExLoop
+// named after `owner`. `args` are the arguments of the circuit or gadget
+// owning `code` and `outputs` are its outputs, which are returned with
+// the Gate indices updated.
+func recoverLoops(owner string, code []App, args []ExArg, outputs []Operand) ([]App, []Operand) {
+	nbLoops := 0
+	for start := 0; start < len(code); start++ {
+		for size := 1; start+2*size <= len(code); size++ {
+			m := &loopMatch{code: code, start: start, size: size, iterated: map[string]bool{}, inits: map[int]Operand{}}
+			count := m.count()
+			if count < 2 {
+				continue
+			}
+			name := fmt.Sprintf("%s_loop_%d", owner, nbLoops)
+			loop, callArgs, ok := m.build(name, count, args, outputs)
+			if !ok {
+				continue
+			}
+			code, outputs = m.replace(loop, callArgs, count, outputs)
+			nbLoops++
+			break
+		}
+	}
+	return code, outputs
+}
+
+// loopMatch is a candidate loop made of blocks of `size` apps starting at
+// `start`. The blocks are matched against the template, which is the
+// second block because its operands can refer to the previous iteration.
+type loopMatch struct {
+	code  []App
+	start int
+	size  int
+	// iterated records whether the Proj at each path of the template are
+	// indexed by the iteration or by a constant
+	iterated map[string]bool
+	// inits are the initial values carried to the first iteration,
+	// by offset of the carried Gate in the block
+	inits map[int]Operand
+}
+
+// count returns the number of consecutive blocks matching the template
+func (m *loopMatch) count() int {
+	if m.start+2*m.size > len(m.code) || !m.matchBlock(0) {
+		return 0
+	}
+	count := 2
+	for m.start+(count+1)*m.size <= len(m.code) && m.matchBlock(count) {
+		count++
+	}
+	return count
+}
+
+func (m *loopMatch) block(k int) []App {
+	return m.code[m.start+k*m.size : m.start+(k+1)*m.size]
+}
+
+// matchBlock checks that the block of iteration `k` is the template
+// with the iteration index and the carried values of iteration `k`
+func (m *loopMatch) matchBlock(k int) bool {
+	template, block := m.block(1), m.block(k)
+	for i := range template {
+		if !sameOp(template[i].Op, block[i].Op) || len(template[i].Args) != len(block[i].Args) {
+			return false
+		}
+		for j := range template[i].Args {
+			if !m.matchOperand(template[i].Args[j], block[i].Args[j], k, fmt.Sprintf("%d.%d", i, j)) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// sameOp checks that `a` and `b` are the same Op. Gadgets are compared
+// by name because each call has its own copy of the ExGadget.
+func sameOp(a, b Op) bool {
+	switch a := a.(type) {
+	case *ExGadget:
+		other, ok := b.(*ExGadget)
+		return ok && a.Name == other.Name
+	case *ExLoop, ExRemoved:
+		// Removed apps are only comments, a loop of them would be empty
+		return false
+	default:
+		return a == b
+	}
+}
+
+// matchOperand checks that the operand `o` of iteration `k` matches
+// the operand `t` at `path` in the template
+func (m *loopMatch) matchOperand(t Operand, o Operand, k int, path string) bool {
+	switch t := t.(type) {
+	case Gate:
+		other, ok := o.(Gate)
+		offset := t.Index - m.start - m.size
+		switch {
+		case offset >= 0:
+			return ok && other.Index == m.start+k*m.size+offset
+		case offset >= -m.size:
+			offset += m.size
+			if k > 0 {
+				return ok && other.Index == m.start+(k-1)*m.size+offset
+			}
+			if m.usesLoop(o) {
+				return false
+			}
+			if init, found := m.inits[offset]; found {
+				return reflect.DeepEqual(init, o)
+			}
+			m.inits[offset] = o
+			return true
+		default:
+			// The types of the gates before the loop aren't known,
+			// therefore they can't be arguments of the loop
+			return false
+		}
+	case Proj:
+		other, ok := o.(Proj)
+		if !ok || t.Size != other.Size {
+			return false
+		}
+		if m.usesLoop(t.Operand) {
+			return t.Index == other.Index && m.matchOperand(t.Operand, other.Operand, k, path+".0")
+		}
+		if !reflect.DeepEqual(t.Operand, other.Operand) {
+			return false
+		}
+		iterated := other.Index == t.Index+k-1
+		if !iterated && other.Index != t.Index {
+			return false
+		}
+		if previous, found := m.iterated[path]; found && previous != iterated {
+			return false
+		}
+		m.iterated[path] = iterated
+		return true
+	case FieldProj:
+		other, ok := o.(FieldProj)
+		return ok && t.Name == other.Name && m.matchOperand(t.Operand, other.Operand, k, path+".0")
+	case ProjArray:
+		other, ok := o.(ProjArray)
+		if !ok || len(t.Projs) != len(other.Projs) {
+			return false
+		}
+		for i := range t.Projs {
+			if !m.matchOperand(t.Projs[i], other.Projs[i], k, fmt.Sprintf("%s.%d", path, i)) {
+				return false
+			}
+		}
+		return true
+	case ProjStruct:
+		other, ok := o.(ProjStruct)
+		if !ok || !reflect.DeepEqual(t.Fields, other.Fields) {
+			return false
+		}
+		for i := range t.Values {
+			if !m.matchOperand(t.Values[i], other.Values[i], k, fmt.Sprintf("%s.%d", path, i)) {
+				return false
+			}
+		}
+		return true
+	default:
+		return reflect.DeepEqual(t, o)
+	}
+}
+
+// usesLoop checks if `op` refers to the gates of the loop
+func (m *loopMatch) usesLoop(op Operand) bool {
+	for _, base := range extractGateVars(op) {
+		if gate, ok := base.(Gate); ok && gate.Index >= m.start {
+			return true
+		}
+	}
+	return false
+}
+
+// loopBuilder collects the arguments of the loop while rewriting the
+// template into the body of the loop
+type loopBuilder struct {
+	*loopMatch
+	args      []ExArg
+	names     []string
+	loop      *ExLoop
+	carries   map[int]int
+	lists     []Operand
+	params    map[int]int
+	paramArgs []Operand
+	ok        bool
+}
+
+// build returns the ExLoop of `count` iterations and the arguments of
+// its call. It fails when the loop doesn't iterate over any vector,
+// when the carried values aren't field elements, or when the code
+// after the loop uses values computed in the loop which aren't carried.
+func (m *loopMatch) build(name string, count int, args []ExArg, outputs []Operand) (*ExLoop, []Operand, bool) {
+	offsets := []int{}
+	for offset := range m.inits {
+		offsets = append(offsets, offset)
+	}
+	sort.Ints(offsets)
+	b := loopBuilder{loopMatch: m, args: args, loop: &ExLoop{Name: name}, carries: map[int]int{}, params: map[int]int{}, ok: true}
+	for i, offset := range offsets {
+		if !isFieldGate(m.block(1)[offset].Op) {
+			return nil, nil, false
+		}
+		b.carries[offset] = i
+	}
+
+	// Code after the loop can only use the values carried out of the loop
+	end := m.start + count*m.size
+	last := end - m.size
+	after := append([]Operand{}, outputs...)
+	for _, app := range m.code[end:] {
+		after = append(after, app.Args...)
+	}
+	for _, op := range after {
+		for _, base := range extractGateVars(op) {
+			gate, ok := base.(Gate)
+			if !ok || gate.Index < m.start || gate.Index >= end {
+				continue
+			}
+			if _, carried := b.carries[gate.Index-last]; gate.Index < last || !carried {
+				return nil, nil, false
+			}
+		}
+	}
+
+	template := m.block(1)
+	body := make([]App, len(template))
+	for i, app := range template {
+		body[i] = App{app.Op, make([]Operand, len(app.Args))}
+		for j, arg := range app.Args {
+			body[i].Args[j] = b.rewrite(arg, fmt.Sprintf("%d.%d", i, j), count)
+		}
+	}
+	if !b.ok || len(b.loop.Lists) == 0 {
+		return nil, nil, false
+	}
+
+	// The arguments of the loop are the lists, the carried values and the
+	// parameters, but lists and parameters are found at the same time
+	loop := b.loop
+	params := loop.Args
+	paramTypes := loop.Types
+	loop.Args, loop.Types = []ExArg{}, []string{}
+	for i, list := range loop.Lists {
+		loop.Args = append(loop.Args, ExArg{Name: b.names[i]})
+		loop.Types = append(loop.Types, fmt.Sprintf("List %s", b.elemType(list.Vector)))
+	}
+	for i := range offsets {
+		loop.Args = append(loop.Args, ExArg{Name: fmt.Sprintf("carry_%d", i)})
+		loop.Types = append(loop.Types, "F")
+	}
+	loop.Args = append(loop.Args, params...)
+	loop.Types = append(loop.Types, paramTypes...)
+	for _, arg := range loop.Args[:len(loop.Lists)] {
+		for _, param := range params {
+			if arg.Name == param.Name {
+				return nil, nil, false
+			}
+		}
+	}
+
+	// Input in the body refers to the arguments followed by the elements
+	nbArgs := len(loop.Args)
+	for i := range body {
+		for j := range body[i].Args {
+			body[i].Args[j] = shiftInputs(body[i].Args[j], len(loop.Lists), len(offsets), nbArgs)
+		}
+	}
+	loop.Code = body
+	loop.Carries = len(offsets)
+	loop.Next = make([]Operand, len(offsets))
+	for i, offset := range offsets {
+		loop.Next[i] = Gate{offset}
+	}
+
+	callArgs := append([]Operand{}, b.lists...)
+	for _, offset := range offsets {
+		callArgs = append(callArgs, m.inits[offset])
+	}
+	callArgs = append(callArgs, b.paramArgs...)
+	return loop, callArgs, true
+}
+
+// isFieldGate checks that the gate of `op` is a field element
+func isFieldGate(op Op) bool {
+	switch op := op.(type) {
+	case *ExGadget:
+		return len(op.OutputsFlat) > 0 && gadgetOutputType(*op) == "F"
+	case OpKind:
+		switch op {
+		case OpAdd, OpMulAcc, OpNegative, OpSub, OpMul, OpCommit,
+			OpDivUnchecked, OpDiv, OpInverse, OpXor, OpOr, OpAnd, OpSelect, OpLookup, OpCmp, OpIsZero, OpFromBinary:
+			return true
+		}
+	}
+	return false
+}
+
+// rewrite returns the operand `t` at `path` in the template as it's used
+// in the body of the loop. While the lists and the parameters are found,
+// Input refers to the position in b.loop.Elems or b.loop.Args, and the
+// carried values are Input with negative index.
+func (b *loopBuilder) rewrite(t Operand, path string, count int) Operand {
+	switch t := t.(type) {
+	case Gate:
+		offset := t.Index - b.start - b.size
+		carry, carried := b.carries[offset+b.size]
+		switch {
+		case offset >= 0:
+			return Gate{offset}
+		case !carried:
+			// Vectors computed before the loop can't be arguments
+			b.ok = false
+		}
+		return Input{-1 - carry}
+	case Input:
+		return Input{b.param(t.Index)}
+	case Proj:
+		if b.usesLoop(t.Operand) || !b.iterated[path] {
+			return Proj{b.rewrite(t.Operand, path+".0", count), t.Index, t.Size}
+		}
+		return Input{-1 - len(b.carries) - b.list(t.Operand, t.Index-1, count, t.Size)}
+	case FieldProj:
+		return FieldProj{b.rewrite(t.Operand, path+".0", count), t.Name}
+	case ProjArray:
+		projs := make([]Operand, len(t.Projs))
+		for i, proj := range t.Projs {
+			projs[i] = b.rewrite(proj, fmt.Sprintf("%s.%d", path, i), count)
+		}
+		return ProjArray{projs}
+	case ProjStruct:
+		values := make([]Operand, len(t.Values))
+		for i, value := range t.Values {
+			values[i] = b.rewrite(value, fmt.Sprintf("%s.%d", path, i), count)
+		}
+		return ProjStruct{t.Fields, values}
+	default:
+		return t
+	}
+}
+
+// param returns the position in the parameters of the argument `index`
+func (b *loopBuilder) param(index int) int {
+	if i, ok := b.params[index]; ok {
+		return i
+	}
+	b.params[index] = len(b.loop.Args)
+	b.loop.Args = append(b.loop.Args, b.args[index])
+	b.loop.Types = append(b.loop.Types, genArgType(b.args[index]))
+	b.paramArgs = append(b.paramArgs, Input{index})
+	return b.params[index]
+}
+
+// list returns the position of the list iterating `vector` from `start`
+func (b *loopBuilder) list(vector Operand, start int, count int, size int) int {
+	for i, list := range b.loop.Lists {
+		if list.Start == start && reflect.DeepEqual(list.Vector, vector) {
+			return i
+		}
+	}
+	name := fmt.Sprintf("list_%d", len(b.loop.Lists))
+	if input, ok := vector.(Input); ok {
+		name = b.args[input.Index].Name
+	}
+	// The same vector can be iterated from different elements
+	if slices.Contains(b.names, name) {
+		name = fmt.Sprintf("%s_%d", name, start)
+	}
+	// Take and drop can't be used on the vectors of generic size
+	t, ok := b.vectorType(vector)
+	if !ok || (t.Param != "" && (start != 0 || count != size)) {
+		b.ok = false
+	}
+	b.loop.Lists = append(b.loop.Lists, loopList{vector, start, count, size})
+	b.names = append(b.names, name)
+	b.loop.Elems = append(b.loop.Elems, name+"_i")
+	b.lists = append(b.lists, vector)
+	return len(b.loop.Lists) - 1
+}
+
+// vectorType returns the type of `vector`, which must be an argument
+// or an element of an argument
+func (b *loopBuilder) vectorType(vector Operand) (ExArgType, bool) {
+	switch op := vector.(type) {
+	case Input:
+		arg := b.args[op.Index]
+		if arg.Kind != reflect.Array && arg.Kind != reflect.Slice {
+			return ExArgType{}, false
+		}
+		return arg.Type, true
+	case Proj:
+		t, ok := b.vectorType(op.Operand)
+		if !ok || t.Type == nil {
+			return ExArgType{}, false
+		}
+		return *t.Type, true
+	default:
+		return ExArgType{}, false
+	}
+}
+
+// elemType returns the Lean type of the elements of `vector`
+func (b *loopBuilder) elemType(vector Operand) string {
+	t, _ := b.vectorType(vector)
+	switch {
+	case t.Type != nil:
+		return fmt.Sprintf("(%s)", genNestedArrays(*t.Type))
+	case t.Struct != "":
+		return t.Struct
+	default:
+		return "F"
+	}
+}
+
+// shiftInputs moves the Input of the body built by rewrite to their
+// final position: the carried values after `nbLists` lists, the
+// parameters after the carried values and the elements after `nbArgs`.
+func shiftInputs(op Operand, nbLists int, nbCarries int, nbArgs int) Operand {
+	switch op := op.(type) {
+	case Input:
+		switch {
+		case op.Index >= 0:
+			return Input{nbLists + nbCarries + op.Index}
+		case op.Index >= -nbCarries:
+			return Input{nbLists - 1 - op.Index}
+		default:
+			return Input{nbArgs - 1 - nbCarries - op.Index}
+		}
+	case Proj:
+		return Proj{shiftInputs(op.Operand, nbLists, nbCarries, nbArgs), op.Index, op.Size}
+	case FieldProj:
+		return FieldProj{shiftInputs(op.Operand, nbLists, nbCarries, nbArgs), op.Name}
+	case ProjArray:
+		projs := make([]Operand, len(op.Projs))
+		for i, proj := range op.Projs {
+			projs[i] = shiftInputs(proj, nbLists, nbCarries, nbArgs)
+		}
+		return ProjArray{projs}
+	case ProjStruct:
+		values := make([]Operand, len(op.Values))
+		for i, value := range op.Values {
+			values[i] = shiftInputs(value, nbLists, nbCarries, nbArgs)
+		}
+		return ProjStruct{op.Fields, values}
+	default:
+		return op
+	}
+}
+
+// replace replaces the `count` blocks of the loop with a call to `loop`
+// and returns the new code and `outputs` with the Gate indices updated
+func (m *loopMatch) replace(loop *ExLoop, callArgs []Operand, count int, outputs []Operand) ([]App, []Operand) {
+	end := m.start + count*m.size
+	last := end - m.size
+	offsets := []int{}
+	for offset := range m.inits {
+		offsets = append(offsets, offset)
+	}
+	sort.Ints(offsets)
+	remap := func(index int) Operand {
+		switch {
+		case index < m.start:
+			return Gate{index}
+		case index >= end:
+			return Gate{index - count*m.size + 1}
+		}
+		i := sort.SearchInts(offsets, index-last)
+		if loop.Carries == 1 {
+			return Gate{m.start}
+		}
+		return Proj{Gate{m.start}, i, loop.Carries}
+	}
+
+	code := append([]App{}, m.code[:m.start]...)
+	code = append(code, App{loop, callArgs})
+	for _, app := range m.code[end:] {
+		args := make([]Operand, len(app.Args))
+		for i, arg := range app.Args {
+			args[i] = remapGates(arg, remap)
+		}
+		code = append(code, App{app.Op, args})
+	}
+	newOutputs := make([]Operand, len(outputs))
+	for i, output := range outputs {
+		newOutputs[i] = remapGates(output, remap)
+	}
+	return code, newOutputs
+}
+
+// remapGates replaces the Gate in `op` with the result of `remap`
+func remapGates(op Operand, remap func(int) Operand) Operand {
+	switch op := op.(type) {
+	case Gate:
+		return remap(op.Index)
+	case Proj:
+		return Proj{remapGates(op.Operand, remap), op.Index, op.Size}
+	case FieldProj:
+		return FieldProj{remapGates(op.Operand, remap), op.Name}
+	case ProjArray:
+		projs := make([]Operand, len(op.Projs))
+		for i, proj := range op.Projs {
+			projs[i] = remapGates(proj, remap)
+		}
+		return ProjArray{projs}
+	case ProjStruct:
+		values := make([]Operand, len(op.Values))
+		for i, value := range op.Values {
+			values[i] = remapGates(value, remap)
+		}
+		return ProjStruct{op.Fields, values}
+	default:
+		return op
+	}
+}
@@ -1,13 +1,13 @@
 package extractor
 
 import (
-	"errors"
-	"flag"
+	"crypto/sha256"
 	"fmt"
+	"math/big"
 	"reflect"
-	"runtime/debug"
 	"strings"
 
+	"github.com/consensys/gnark/constraint/solver"
 	"github.com/consensys/gnark/frontend"
 	"github.com/consensys/gnark/frontend/schema"
 	"github.com/mitchellh/copystructure"
@@ -15,18 +15,16 @@ import (
 )
 
 // recoverError is used in the top level interface to prevent panic
-// caused by any of the methods in the extractor from propagating
-// When go is running in test mode, it prints the stack trace to aid
-// debugging.
-func recoverError() (err error) {
-	if recover() != nil {
-		if flag.Lookup("test.v") != nil {
-			stack := string(debug.Stack())
-			fmt.Println(stack)
-		}
-		err = errors.New("Panic extracting circuit to Lean")
+// caused by any of the methods in the extractor from propagating,
+// and sets `err` to the ExtractionError describing the panic.
+// Errors returned without panicking (i.e. by the Define method of
+// circuits) are wrapped in an ExtractionError too.
+func recoverError(err *error) {
+	if r := recover(); r != nil {
+		*err = toExtractionError(r)
+	} else if *err != nil {
+		*err = toExtractionError(*err)
 	}
-	return nil
 }
 
 // arrayToSlice returns a slice of elements identical to
@@ -54,20 +52,26 @@ func arrayToSlice(v reflect.Value) []frontend.Variable {
 			res = append(res, v.Index(i).Elem().Interface().(frontend.Variable))
 		}
 		return res
+	case reflect.Struct:
+		res := []frontend.Variable{}
+		for i := 0; i < v.Len(); i++ {
+			res = append(res, v.Index(i).Interface())
+		}
+		return res
 	default:
 		return []frontend.Variable{}
 	}
 }
 
-// flattenSlice takes a slice and returns a single dimension
-// slice of frontend.Variable. This is needed to transform
-// nested slices into single dimensional slices to be
-// processed by sanitizeVars.
+// flattenSlice takes a slice or an array and returns a single
+// dimension slice of frontend.Variable. This is needed to
+// transform nested slices into single dimensional slices to
+// be processed by sanitizeVars.
 func flattenSlice(value reflect.Value) []frontend.Variable {
 	if value.Len() == 0 {
 		return []frontend.Variable{}
 	}
-	if value.Index(0).Kind() == reflect.Slice {
+	if value.Index(0).Kind() == reflect.Slice || value.Index(0).Kind() == reflect.Array {
 		args := []frontend.Variable{}
 		for i := 0; i < value.Len(); i++ {
 			arg := flattenSlice(value.Index(i))
@@ -79,32 +83,170 @@ func flattenSlice(value reflect.Value) []frontend.Variable {
 		}
 		return args
 	}
+	if isStruct(value.Index(0)) {
+		args := make([]frontend.Variable, value.Len())
+		for i := range args {
+			args[i] = value.Index(i).Interface()
+		}
+		return args
+	}
+	if value.Kind() == reflect.Array {
+		return arrayToSlice(value)
+	}
 	return value.Interface().([]frontend.Variable)
 }
 
+// hasVectorOutputs returns true if the outputs of `gadget`
+// are a slice or an array
+func hasVectorOutputs(gadget ExGadget) bool {
+	kind := reflect.ValueOf(gadget.Outputs).Kind()
+	return kind == reflect.Slice || kind == reflect.Array
+}
+
+// isStruct checks if `v` is a struct grouping variables, as opposed
+// to the structs used to represent a single variable (i.e. Operand)
+func isStruct(v reflect.Value) bool {
+	if v.Kind() != reflect.Struct {
+		return false
+	}
+	if _, ok := v.Interface().(Operand); ok {
+		return false
+	}
+	return v.Type() != reflect.TypeOf(big.Int{})
+}
+
+// exportedFields returns the fields of the struct `v` which
+// can be accessed by the extractor and may contain variables
+func exportedFields(v reflect.Value) []reflect.StructField {
+	fields := []reflect.StructField{}
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Type().Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		switch f.Type.Kind() {
+		case reflect.Interface, reflect.Slice, reflect.Array, reflect.Struct:
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// structOperand generates the ProjStruct for the struct `v`. If all the fields
+// of `v` are the fields of the same Operand, that Operand is returned instead.
+func structOperand(v reflect.Value) Operand {
+	fields := exportedFields(v)
+	res := ProjStruct{make([]string, len(fields)), make([]Operand, len(fields))}
+	for i, f := range fields {
+		res.Fields[i] = f.Name
+		value := v.FieldByIndex(f.Index)
+		switch value.Kind() {
+		case reflect.Slice:
+			res.Values[i] = ProjArray{sanitizeVars(flattenSlice(value)...)}
+		case reflect.Array:
+			res.Values[i] = ProjArray{sanitizeVars(arrayToSlice(value)...)}
+		default:
+			res.Values[i] = sanitizeVars(value.Interface())[0]
+		}
+	}
+
+	if len(res.Values) == 0 {
+		return res
+	}
+	var first FieldProj
+	for i, value := range res.Values {
+		if projArray, ok := value.(ProjArray); ok {
+			if isComplete, op := isVectorComplete(projArray); isComplete {
+				value = op
+			}
+		}
+		fieldProj, ok := value.(FieldProj)
+		if i == 0 {
+			first = fieldProj
+		}
+		if !ok || fieldProj.Name != res.Fields[i] || !reflect.DeepEqual(fieldProj.Operand, first.Operand) {
+			return res
+		}
+	}
+	return first.Operand
+}
+
+// structName generates the name of the Lean structure for the struct `v`.
+// Similarly to gadgets, structs with slice fields of different sizes
+// are distinct structures, therefore the sizes are added as suffix.
+func structName(v reflect.Value) string {
+	name := v.Type().Name()
+	if name == "" {
+		fail(ErrUnsupportedType, v.Interface(), "Anonymous struct %s isn't supported", v.Type())
+	}
+	for _, size := range structSizes(v) {
+		name += fmt.Sprintf("_%d", size)
+	}
+	return name
+}
+
+// structSizes returns the lengths of the slices in the struct `v`
+func structSizes(v reflect.Value) []int {
+	sizes := []int{}
+	switch {
+	case isStruct(v):
+		for _, f := range exportedFields(v) {
+			sizes = append(sizes, structSizes(v.FieldByIndex(f.Index))...)
+		}
+	case v.Kind() == reflect.Slice || v.Kind() == reflect.Array:
+		sizes = append(sizes, v.Len())
+		if v.Len() > 0 {
+			sizes = append(sizes, structSizes(v.Index(0))...)
+		}
+	}
+	return sizes
+}
+
 // arrayInit generates the Proj{} object for each element of v
 func arrayInit(f schema.Field, v reflect.Value, op Operand) error {
 	for i := 0; i < f.ArraySize; i++ {
 		op := Proj{op, i, f.ArraySize}
 		switch len(f.SubFields) {
 		case 1:
-			arrayInit(f.SubFields[0], v.Index(i), op)
+			if f.SubFields[0].Type == schema.Struct {
+				structInit(f.SubFields[0], v.Index(i), op)
+			} else {
+				arrayInit(f.SubFields[0], v.Index(i), op)
+			}
 		case 0:
 			if v.Len() != f.ArraySize {
 				// Slices of this type aren't supported yet [[<nil> <nil> <nil>] [<nil> <nil>]]
 				// gnark newSchema doesn't handle different dimensions
-				fmt.Printf("Wrong slices dimensions %+v\n", v)
-				panic("Only slices dimensions not matching")
+				fail(ErrUnsupportedType, v.Interface(), "Wrong slices dimensions %+v", v)
 			}
 			value := reflect.ValueOf(op)
 			v.Index(i).Set(value)
 		default:
-			panic("Only nested arrays supported in SubFields")
+			fail(ErrUnsupportedType, v.Interface(), "Only nested arrays supported in SubFields")
 		}
 	}
 	return nil
 }
 
+// structInit generates the FieldProj{} object for each field of v
+func structInit(f schema.Field, v reflect.Value, op Operand) {
+	for _, sub := range f.SubFields {
+		field := v.FieldByName(sub.Name)
+		op := FieldProj{op, sub.Name}
+		switch field.Kind() {
+		case reflect.Array:
+			arrayInit(sub, field, op)
+		case reflect.Slice:
+			arrayZero(field)
+			arrayInit(sub, field, op)
+		case reflect.Struct:
+			structInit(sub, field, op)
+		case reflect.Interface:
+			field.Set(reflect.ValueOf(op))
+		}
+	}
+}
+
 // arrayZero sets all the elements of the input slice v to nil.
 // It is used when initialising a new circuit or gadget to ensure
 // the object is clean
@@ -118,13 +260,120 @@ func arrayZero(v reflect.Value) {
 				for i := 0; i < v.Len(); i++ {
 					arrayZero(v.Addr().Elem().Index(i))
 				}
+			} else if v.Index(0).Kind() == reflect.Struct {
+				// The elements are copied to keep the slices they contain,
+				// which are then zeroed in turn
+				zero_array := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+				reflect.Copy(zero_array, v)
+				v.Set(zero_array)
+				for i := 0; i < v.Len(); i++ {
+					structZero(v.Index(i))
+				}
 			} else {
 				zero_array := make([]frontend.Variable, v.Len(), v.Len())
 				v.Set(reflect.ValueOf(&zero_array).Elem())
 			}
 		}
 	default:
-		panic("Only nested slices supported in SubFields of slices")
+		fail(ErrUnsupportedType, v.Interface(), "Only nested slices supported in SubFields of slices")
+	}
+}
+
+// structZero calls arrayZero on the slices in the struct v
+func structZero(v reflect.Value) {
+	for _, f := range exportedFields(v) {
+		switch field := v.FieldByIndex(f.Index); field.Kind() {
+		case reflect.Slice:
+			arrayZero(field)
+		case reflect.Struct:
+			structZero(field)
+		}
+	}
+}
+
+// exField is a field of a circuit or gadget with its value.
+// The elements of ragged slices are separate exField.
+type exField struct {
+	schema.Field
+	Value reflect.Value
+}
+
+// getExFields returns the exField of the struct `v` corresponding
+// to `fields`. Ragged slices are replaced by their elements.
+func getExFields(v reflect.Value, fields []schema.Field) []exField {
+	res := []exField{}
+	for _, f := range fields {
+		value := v.FieldByName(f.Name)
+		if !isRagged(value) {
+			res = append(res, exField{f, value})
+			continue
+		}
+		for _, part := range splitRagged(f.Name, value) {
+			res = append(res, exField{valueField(part.Name, part.Value), part.Value})
+		}
+	}
+	return res
+}
+
+// splitRagged splits the ragged slice `v` named `name` in its elements,
+// recursively. Each element is named after its index (i.e. `Path_1`).
+// Values which aren't ragged slices, including the structs in ragged
+// slices of structs, are returned as they are.
+func splitRagged(name string, v reflect.Value) []exField {
+	if !isRagged(v) {
+		return []exField{{schema.Field{Name: name}, v}}
+	}
+	res := []exField{}
+	for i := 0; i < v.Len(); i++ {
+		// Empty slices aren't arguments, same as in gnark schema
+		if elem := v.Index(i); isStruct(elem) || elem.Len() != 0 {
+			res = append(res, splitRagged(fmt.Sprintf("%s_%d", name, i), v.Index(i))...)
+		}
+	}
+	return res
+}
+
+// isRagged checks if the nested slices in `v` have different dimensions,
+// or if the structs in the slice `v` contain slices of different sizes
+func isRagged(v reflect.Value) bool {
+	if v.Kind() != reflect.Slice || v.Len() == 0 {
+		return false
+	}
+	first := v.Index(0)
+	if first.Kind() != reflect.Slice && first.Kind() != reflect.Array && !isStruct(first) {
+		return false
+	}
+	for i := 0; i < v.Len(); i++ {
+		if isRagged(v.Index(i)) || !reflect.DeepEqual(structSizes(v.Index(i)), structSizes(first)) {
+			return true
+		}
+	}
+	return false
+}
+
+// valueField generates the schema.Field named `name` of the value `v`.
+// The gnark schema of slices only contains the dimensions of their first
+// element, which are wrong for the elements of ragged slices.
+func valueField(name string, v reflect.Value) schema.Field {
+	switch {
+	case isStruct(v):
+		field := schema.Field{Name: name, Type: schema.Struct}
+		for _, f := range exportedFields(v) {
+			sub := v.FieldByIndex(f.Index)
+			// Empty slices aren't in the schema, same as in gnark
+			if (sub.Kind() != reflect.Slice && sub.Kind() != reflect.Array) || sub.Len() != 0 {
+				field.SubFields = append(field.SubFields, valueField(f.Name, sub))
+			}
+		}
+		return field
+	case v.Kind() == reflect.Slice || v.Kind() == reflect.Array:
+		field := schema.Field{Name: name, Type: schema.Array, ArraySize: v.Len()}
+		if v.Len() != 0 && v.Index(0).Kind() != reflect.Interface {
+			field.SubFields = []schema.Field{valueField("", v.Index(0))}
+		}
+		return field
+	default:
+		return schema.Field{Name: name, Type: schema.Leaf}
 	}
 }
 
@@ -140,68 +389,93 @@ func getStructName(a any) string {
 	return reflect.TypeOf(a).Elem().Name()
 }
 
-// updateProj recursively creates a Proj object using the `Index` and `Size` from the
-// optional argument `extra`. It uses the argument `gate` as Operand for the innermost Proj.
-// The `extra` optional argument contains the `Index` in even indices and the `Size` in odd indices,
-// elements are discarded from the end.
-func updateProj(gate Operand, extra ...int) Proj {
-	if len(extra) == 2 {
-		return Proj{gate, extra[0], extra[1]}
-	} else if len(extra) > 0 && len(extra)%2 == 0 {
-		return Proj{updateProj(gate, extra[:len(extra)-2]...), extra[len(extra)-2], extra[len(extra)-1]}
-	}
-	fmt.Printf("updateProj gate: %#v | extra: %+v", gate, extra)
-	panic("updateProj called with wrong number of elements in extra")
-}
-
 // replaceArg generates the object returned when calling the gadget in a circuit.
-// The object returned has the same structure as ExGadget.OutputsFlat but it needs
-// to have updated `Proj` fields. gate argument corresponds to the `Gate` object of the
-// gadget call. extra argument keeps track of the `Size` and `Index` elements of the nested
-// Proj. These need to be replaced because the output of a gadget is a combination
-// of Proj.
-func replaceArg(gOutputs interface{}, gate Operand, extra ...int) interface{} {
-	// extra[0] -> i
-	// extra[1] -> len
-	switch v := (gOutputs).(type) {
-	case Input, Gate:
-		if len(extra) == 2 {
-			return Proj{gate, extra[0], extra[1]}
-		}
-		return gate
-	case Proj:
-		if len(extra) >= 2 {
-			return updateProj(gate, extra...)
+// The object returned has the same type as ExGadget.Outputs, but its elements
+// are replaced by the `Proj` (or `FieldProj` for structs) of the gadget call
+// they correspond to. gate argument
+// is the `Gate` object of the gadget call at the top level, and the `Proj` of
+// the enclosing slice in the nested calls. These need to be replaced because
+// the output of a gadget is a combination of Proj. The fields of structs
+// which can't contain variables are copied from `gOutputs`.
+func replaceArg(gOutputs interface{}, gate Operand) interface{} {
+	v := reflect.ValueOf(gOutputs)
+	var res reflect.Value
+	switch {
+	case isStruct(v):
+		res = reflect.New(v.Type()).Elem()
+		res.Set(v)
+		for _, f := range exportedFields(v) {
+			elem := replaceArg(v.FieldByIndex(f.Index).Interface(), FieldProj{gate, f.Name})
+			setReplaced(res.FieldByIndex(f.Index), elem)
 		}
+	case v.Kind() == reflect.Slice:
+		res = reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		reflect.Copy(res, v)
+	case v.Kind() == reflect.Array:
+		res = reflect.New(v.Type()).Elem()
+		res.Set(v)
+	default:
 		return gate
-	case []frontend.Variable:
-		res := make([]frontend.Variable, len(v))
-		for i, o := range v {
-			res[i] = replaceArg(o, gate, append(extra, []int{i, len(v)}...)...)
-		}
-		return res
-	case [][]frontend.Variable:
-		res := make([][]frontend.Variable, len(v))
-		for i, o := range v {
-			res[i] = replaceArg(o, gate, append(extra, []int{i, len(v)}...)...).([]frontend.Variable)
-		}
-		return res
-	case [][][]frontend.Variable:
-		res := make([][][]frontend.Variable, len(v))
-		for i, o := range v {
-			res[i] = replaceArg(o, gate, append(extra, []int{i, len(v)}...)...).([][]frontend.Variable)
+	}
+	if !isStruct(v) {
+		for i := 0; i < v.Len(); i++ {
+			elem := replaceArg(v.Index(i).Interface(), Proj{gate, i, v.Len()})
+			setReplaced(res.Index(i), elem)
 		}
-		return res
-	case nil:
-		return []frontend.Variable{}
+	}
+	return res.Interface()
+}
+
+// setReplaced sets `dst` to the value `elem` generated by replaceArg.
+// Values which can't contain variables (i.e. int fields) are kept.
+func setReplaced(dst reflect.Value, elem interface{}) {
+	v := reflect.ValueOf(elem)
+	if v.IsValid() && v.Type().AssignableTo(dst.Type()) {
+		dst.Set(v)
+	}
+}
+
+// valueArgType generates the ExArgType of the value `v`
+func valueArgType(v reflect.Value) ExArgType {
+	switch {
+	case isStruct(v):
+		return ExArgType{Struct: structName(v)}
+	case v.Kind() != reflect.Slice && v.Kind() != reflect.Array:
+		return ExArgType{}
+	case v.Len() == 0:
+		return ExArgType{}
+	case v.Index(0).Kind() == reflect.Slice || v.Index(0).Kind() == reflect.Array:
+		subType := valueArgType(v.Index(0))
+		return ExArgType{Size: v.Len(), Type: &subType}
 	default:
-		fmt.Printf("replaceArg invalid argument of type %T %#v\n", gOutputs, gOutputs)
-		panic("replaceArg invalid argument")
+		return ExArgType{Size: v.Len(), Struct: valueArgType(v.Index(0)).Struct}
 	}
 }
 
+// innerStruct returns the struct contained in the (nested) arrays `v`.
+// The returned value isn't valid if there is no struct.
+func innerStruct(v reflect.Value) reflect.Value {
+	for (v.Kind() == reflect.Slice || v.Kind() == reflect.Array) && v.Len() > 0 {
+		v = v.Index(0)
+	}
+	if !isStruct(v) {
+		return reflect.Value{}
+	}
+	return v
+}
+
+// defineGadget calls the DefineGadget method of `gadget`. Reflection is
+// needed because the method of abstractor.GadgetDefinitionT can return any type.
+func defineGadget(gadget any, api frontend.API) interface{} {
+	method := reflect.ValueOf(gadget).MethodByName("DefineGadget")
+	if !method.IsValid() {
+		fail(ErrUnsupportedType, gadget, "Gadget %T doesn't have a DefineGadget method", gadget)
+	}
+	return method.Call([]reflect.Value{reflect.ValueOf(api)})[0].Interface()
+}
+
 // cloneGadget performs deep cloning of `gadget`
-func cloneGadget(gadget abstractor.GadgetDefinition) abstractor.GadgetDefinition {
+func cloneGadget(gadget any) any {
 	dup, err := copystructure.Copy(gadget)
 	if err != nil {
 		panic(err)
@@ -210,7 +484,7 @@ func cloneGadget(gadget abstractor.GadgetDefinition) abstractor.GadgetDefinition
 	v := reflect.ValueOf(dup)
 	tmp_gadget := reflect.New(v.Type())
 	tmp_gadget.Elem().Set(v)
-	return tmp_gadget.Interface().(abstractor.GadgetDefinition)
+	return tmp_gadget.Interface()
 }
 
 // generateUniqueName is a function that generates the gadget function name in Lean
@@ -234,14 +508,84 @@ func generateUniqueName(element any, args []ExArg) string {
 			suffix += fmt.Sprintf("_%d", val.Field(i).Int())
 		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
 			suffix += fmt.Sprintf("_%d", val.Field(i).Uint())
-		case reflect.Uintptr, reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
-			fmt.Printf("-- Gadget name doesn't differentiate yet between different values of type %+v.\n", val.Field(i).Kind())
-			fmt.Println("-- Proceed with caution")
+		case reflect.Struct, reflect.Array, reflect.Slice:
+			// Structs with slices of different sizes are different arguments,
+			// and so are the elements of ragged slices of structs
+			for _, part := range splitRagged("", val.Field(i)) {
+				if inner := innerStruct(part.Value); inner.IsValid() {
+					for _, size := range structSizes(inner) {
+						suffix += fmt.Sprintf("_%d", size)
+					}
+				}
+			}
 		}
 	}
 	return fmt.Sprintf("%s%s", reflect.TypeOf(element).Elem().Name(), suffix)
 }
 
+// integerFields returns the values of the integer fields of `gadget`,
+// which are part of the name of the gadget (see generateUniqueName)
+func integerFields(gadget any) map[string]*big.Int {
+	ints := map[string]*big.Int{}
+	val := reflect.ValueOf(gadget).Elem()
+	for i := 0; i < val.NumField(); i++ {
+		name := val.Type().Field(i).Name
+		switch val.Field(i).Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			ints[name] = big.NewInt(val.Field(i).Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			ints[name] = new(big.Int).SetUint64(val.Field(i).Uint())
+		}
+	}
+	return ints
+}
+
+// gadgetKey identifies the definition of the `gadget` named `name`. It
+// contains the values of all the fields which don't contain variables,
+// including the nested ones (i.e. floats in struct fields), so gadgets
+// with the same key have the same definition.
+func gadgetKey(gadget any, name string) string {
+	return fmt.Sprintf("%s %s%s", reflect.TypeOf(gadget), name, constantData(reflect.ValueOf(gadget).Elem()))
+}
+
+// constantData returns the values in `v` which aren't variables. Variables
+// are skipped because they are replaced by the arguments of the gadget.
+func constantData(v reflect.Value) string {
+	switch {
+	case v.Kind() == reflect.Interface:
+		return ""
+	case isStruct(v):
+		data := ""
+		for i := 0; i < v.NumField(); i++ {
+			if fieldData := constantData(v.Field(i)); fieldData != "" {
+				data += fmt.Sprintf(" %s={%s }", v.Type().Field(i).Name, fieldData)
+			}
+		}
+		return data
+	case v.Kind() == reflect.Slice || v.Kind() == reflect.Array:
+		data := ""
+		for i := 0; i < v.Len(); i++ {
+			if elemData := constantData(v.Index(i)); elemData != "" {
+				data += fmt.Sprintf(" %d={%s }", i, elemData)
+			}
+		}
+		return data
+	default:
+		return fmt.Sprintf(" %#v", v)
+	}
+}
+
+// gadgetHash returns the hash of the Lean definition of `gadget`,
+// excluding its name. Gadgets with the same hash are interchangeable.
+func gadgetHash(gadget ExGadget) string {
+	outputType := ""
+	if len(gadget.OutputsFlat) > 0 {
+		outputType = genKTypeSignature(reflect.ValueOf(gadget.Outputs))
+	}
+	definition := fmt.Sprintf("%s\n%s\n%s", genArgs(gadget.Args), outputType, genGadgetBody(gadget.Args, gadget))
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(definition)))
+}
+
 // getGadgetByName checks if `name` matches the ExGadget.Name of one of
 // the elements in `gadgets`
 func getGadgetByName(gadgets []ExGadget, name string) abstractor.Gadget {
@@ -262,3 +606,10 @@ func getSizeGadgetArgs(elem ExArgType) []string {
 	}
 	return append(getSizeGadgetArgs(*elem.Type), fmt.Sprintf("%d", elem.Size))
 }
+
+// hintName returns the name of the hint function `f` without
+// the import path of its package (i.e. `bits.nTrits`)
+func hintName(f solver.Hint) string {
+	name := solver.GetHintName(f)
+	return name[strings.LastIndex(name, "/")+1:]
+}
@@ -0,0 +1,241 @@
+// This file contains the options used to customise the extraction.
+package extractor
+
+import (
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/consensys/gnark-crypto/ecc"
+)
+
+// Config contains the settings of an extraction. It is populated
+// with the Option arguments of the functions in the public API.
+// The passes run on the extracted code are selected by the boolean
+// fields, which are all disabled by default.
+type Config struct {
+	// Namespace is the Lean namespace of the extracted code. If empty,
+	// it's the struct name of the circuit or gadget.
+	Namespace string
+	// Field is the curve whose scalar field is used by the circuit
+	Field ecc.ID
+	// Target is the version of ProvenZK the Lean code is written for
+	Target Target
+	// Imports are the Lean modules imported after the ones of Target
+	Imports []string
+	// SetOptions are the `set_option` directives added to the header
+	SetOptions []string
+	// Output is the writer of the Lean code
+	Output io.Writer
+	// BooleanFacts adds a `Gates.is_bool` statement to the Lean code
+	// for every variable marked with MarkBoolean which isn't already
+	// known to be boolean. AssertIsBoolean is then skipped on it.
+	BooleanFacts bool
+	// PrintlnComments exports the calls to Println as Lean comments
+	// in place of dropping them.
+	PrintlnComments bool
+	// PolymorphicGadgets exports the instantiations of a gadget with
+	// different sizes or integer fields as a single definition, when
+	// possible.
+	PolymorphicGadgets bool
+	// LoopRecovery exports the blocks of code repeated over the elements
+	// of vectors as recursive definitions, in place of unrolling them.
+	LoopRecovery bool
+	// CommonSubexpressions reuses the gates of the operations repeated
+	// with the same arguments in place of exporting them again.
+	CommonSubexpressions bool
+	// DeadGates replaces the gates which don't affect the constraints
+	// with comments reporting their removal.
+	DeadGates bool
+	// InlineBelow inlines the gadgets with fewer than InlineBelow
+	// gates in their body, if it's greater than zero.
+	InlineBelow int
+	// Inline contains the names of the gadgets to be inlined
+	Inline map[string]bool
+	// Diagnostics collects the diagnostics of the extraction, if not nil
+	Diagnostics *Diagnostics
+	// Handler receives the diagnostics of the extraction, if not nil
+	Handler slog.Handler
+	// Strict turns the warnings into errors
+	Strict bool
+}
+
+// Option is used to change the default Config of an extraction
+type Option func(*Config)
+
+// WithNamespace sets the Lean namespace of the extracted code
+func WithNamespace(namespace string) Option {
+	return func(c *Config) {
+		c.Namespace = namespace
+	}
+}
+
+// WithField sets the curve whose scalar field is the field of the circuit
+func WithField(field ecc.ID) Option {
+	return func(c *Config) {
+		c.Field = field
+	}
+}
+
+// WithTarget writes the Lean code for the version of ProvenZK of `target`.
+// The default target is ProvenZK14.
+func WithTarget(target Target) Option {
+	target = target.clone()
+	return func(c *Config) {
+		c.Target = target
+	}
+}
+
+// WithGates replaces the Gates variant of the target with `gates`. The
+// variant must match the semantics of the gnark version of the circuit.
+func WithGates(gates GatesVariant) Option {
+	return func(c *Config) {
+		c.Target.Gates = gates
+	}
+}
+
+// WithImports adds `import` directives for `modules` to the header
+// of the Lean code, after the ones of the target.
+func WithImports(modules ...string) Option {
+	return func(c *Config) {
+		c.Imports = append(c.Imports, modules...)
+	}
+}
+
+// WithSetOptions adds `set_option` directives to the header of the Lean
+// code. Each option is the name of the option followed by its value
+// (i.e. `maxHeartbeats 400000`).
+func WithSetOptions(options ...string) Option {
+	return func(c *Config) {
+		c.SetOptions = append(c.SetOptions, options...)
+	}
+}
+
+// WithOutput makes Extract and ExtractGadget write the Lean code to `w`
+func WithOutput(w io.Writer) Option {
+	return func(c *Config) {
+		c.Output = w
+	}
+}
+
+// WithBooleanFacts makes the extractor state in Lean that the variables
+// marked with MarkBoolean are boolean. gnark trusts MarkBoolean without
+// adding a constraint, therefore this option should only be used when the
+// marked variables are known to be constrained to be boolean.
+func WithBooleanFacts() Option {
+	return func(c *Config) {
+		c.BooleanFacts = true
+	}
+}
+
+// WithPrintlnComments exports each call to Println as a `--` comment
+// containing the Lean expressions of its arguments.
+func WithPrintlnComments() Option {
+	return func(c *Config) {
+		c.PrintlnComments = true
+	}
+}
+
+// WithPolymorphicGadgets exports the instantiations of a gadget as Lean
+// definitions taking the sizes of their arguments as implicit parameters.
+// The integer fields with different values in the instantiations are
+// arguments of type ℕ following the others, which replace the constants
+// equal to their values. Instantiations are grouped in a definition when
+// their bodies only differ by those sizes and integers and don't index a
+// vector whose size is a parameter. The first group is named after the
+// gadget, the next ones are numbered (i.e. `Gadget_generic_1`) and the
+// instantiations left out keep their own definition.
+func WithPolymorphicGadgets() Option {
+	return func(c *Config) {
+		c.PolymorphicGadgets = true
+	}
+}
+
+// WithLoopRecovery exports the loops iterating over vectors as recursive
+// Lean definitions over the lists of their elements. A loop is recovered
+// when consecutive blocks of code only differ by the index of the vector
+// elements and by the values carried from the previous block, and the
+// code after the loop only uses the values carried out of the last block.
+func WithLoopRecovery() Option {
+	return func(c *Config) {
+		c.LoopRecovery = true
+	}
+}
+
+// WithCommonSubexpressions makes the extractor reuse the result of an
+// operation or of a gadget call when it's repeated with the same arguments.
+// Hints and the gadgets using them aren't reused because their outputs
+// aren't determined by the arguments.
+func WithCommonSubexpressions() Option {
+	return func(c *Config) {
+		c.CommonSubexpressions = true
+	}
+}
+
+// WithDeadGateElimination removes the gates without side effects, such as
+// arithmetic operations and hints, whose results aren't used by assertions,
+// callback-style operations, gadget calls or gadget outputs. Each removed
+// gate is reported by a `-- removed` comment in place of its definition.
+func WithDeadGateElimination() Option {
+	return func(c *Config) {
+		c.DeadGates = true
+	}
+}
+
+// WithInlineBelow exports the gadgets whose body has fewer than `n` gates
+// in the code of their callers, in place of a separate Lean definition.
+// Gadgets can also choose to be inlined by implementing abstractor.Inliner.
+func WithInlineBelow(n int) Option {
+	return func(c *Config) {
+		c.InlineBelow = n
+	}
+}
+
+// WithInline exports the gadgets named `names` in the code of their callers.
+// A name is either the name of the Go type of the gadget or the name of its
+// Lean definition, which includes the sizes of the arguments (i.e. `Gadget_3`).
+func WithInline(names ...string) Option {
+	return func(c *Config) {
+		if c.Inline == nil {
+			c.Inline = map[string]bool{}
+		}
+		for _, name := range names {
+			c.Inline[name] = true
+		}
+	}
+}
+
+// WithDiagnostics appends the diagnostics reported during the
+// extraction to `d`, including the ones reported before an error.
+// Extract and ExtractGadget also return them: this option is used
+// to get them from the functions returning the Lean code as a string.
+func WithDiagnostics(d *Diagnostics) Option {
+	return func(c *Config) {
+		c.Diagnostics = d
+	}
+}
+
+// WithSlogHandler sends the diagnostics reported during the extraction to
+// `h` as log records with the attributes `gadget` and `field`, if known.
+func WithSlogHandler(h slog.Handler) Option {
+	return func(c *Config) {
+		c.Handler = h
+	}
+}
+
+// WithStrict makes the extraction fail with an error of kind ErrWarning
+// at the first warning.
+func WithStrict() Option {
+	return func(c *Config) {
+		c.Strict = true
+	}
+}
+
+// newConfig returns the default Config modified by `opts`
+func newConfig(opts ...Option) Config {
+	config := Config{Field: ecc.BN254, Target: ProvenZK14(), Output: os.Stdout}
+	for _, opt := range opts {
+		opt(&config)
+	}
+	return config
+}
@@ -0,0 +1,571 @@
+// This file contains the passes run on the extracted code before
+// exporting it to Lean. They are enabled with the Option arguments
+// of the public API.
+package extractor
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"golang.org/x/exp/slices"
+)
+
+// runPasses runs the passes enabled in ce.Config on the gadgets and
+// on the code of `circuits`, if any.
+func (ce *CodeExtractor) runPasses(circuits []*ExCircuit) {
+	if ce.Config.InlineBelow > 0 || len(ce.Config.Inline) > 0 || ce.hasInliner() {
+		ce.inlineGadgets(circuits)
+	}
+	if ce.Config.CommonSubexpressions {
+		for _, c := range circuits {
+			c.Code, _ = eliminateCommonSubexpressions(c.Code, nil)
+		}
+		for i, g := range ce.Gadgets {
+			ce.Gadgets[i].Code, ce.Gadgets[i].OutputsFlat = eliminateCommonSubexpressions(g.Code, g.OutputsFlat)
+		}
+	}
+	if ce.Config.DeadGates {
+		for _, c := range circuits {
+			if removed := removeDeadGates(c.Code, nil); removed > 0 {
+				ce.report(SeverityInfo, "", "", "removed %d unused gates of %s", removed, c.Name)
+			}
+		}
+		for _, g := range ce.Gadgets {
+			if removed := removeDeadGates(g.Code, g.OutputsFlat); removed > 0 {
+				ce.report(SeverityInfo, g.Name, "", "removed %d unused gates", removed)
+			}
+		}
+	}
+	if ce.Config.PolymorphicGadgets {
+		ce.generaliseGadgets(circuits)
+	}
+	if ce.Config.LoopRecovery {
+		ce.recoverLoops(circuits)
+	}
+}
+
+// eliminateCommonSubexpressions removes the apps of `code` which are
+// the same as a previous app, and replaces their gates with the gate of
+// the previous app. It returns the new code and `outputs` updated.
+func eliminateCommonSubexpressions(code []App, outputs []Operand) ([]App, []Operand) {
+	res := []App{}
+	gates := make([]Operand, len(code))
+	remap := func(index int) Operand {
+		return gates[index]
+	}
+	for i, app := range code {
+		args := make([]Operand, len(app.Args))
+		for j, arg := range app.Args {
+			args[j] = remapGates(arg, remap)
+		}
+		app = App{app.Op, args}
+		gates[i] = Gate{len(res)}
+		if isDeterministic(app.Op) {
+			for j, prev := range res {
+				if sameOp(prev.Op, app.Op) && reflect.DeepEqual(prev.Args, app.Args) {
+					gates[i] = Gate{j}
+					break
+				}
+			}
+		}
+		if gates[i] == (Gate{len(res)}) {
+			res = append(res, app)
+		}
+	}
+	newOutputs := make([]Operand, len(outputs))
+	for i, output := range outputs {
+		newOutputs[i] = remapGates(output, remap)
+	}
+	return res, newOutputs
+}
+
+// isDeterministic checks that the gate of `op` is determined by its
+// arguments. Assertions are excluded because they don't have a gate.
+func isDeterministic(op Op) bool {
+	switch op := op.(type) {
+	case *ExGadget:
+		if len(op.OutputsFlat) == 0 {
+			return false
+		}
+		for _, app := range op.Code {
+			if _, ok := app.Op.(ExHint); ok {
+				return false
+			}
+			if g, ok := app.Op.(*ExGadget); ok && len(g.OutputsFlat) > 0 && !isDeterministic(g) {
+				return false
+			}
+		}
+		return true
+	case OpKind:
+		switch op {
+		case OpAdd, OpMulAcc, OpNegative, OpSub, OpMul, OpCommit,
+			OpDivUnchecked, OpDiv, OpInverse, OpXor, OpOr, OpAnd, OpSelect, OpLookup, OpCmp, OpIsZero, OpToBinary, OpFromBinary:
+			return true
+		}
+	}
+	return false
+}
+
+// removeDeadGates replaces the apps of `code` without side effects whose
+// gates aren't used, directly or through other gates, by the apps with
+// side effects or by `outputs`. The apps are replaced with ExRemoved in
+// place, so that the indices of the other gates don't change. It returns
+// the number of apps removed.
+func removeDeadGates(code []App, outputs []Operand) int {
+	removed := 0
+	live := make([]bool, len(code))
+	markLive := func(operands []Operand) {
+		for _, op := range operands {
+			for _, base := range extractGateVars(op) {
+				if gate, ok := base.(Gate); ok {
+					live[gate.Index] = true
+				}
+			}
+		}
+	}
+	markLive(outputs)
+	for i := len(code) - 1; i >= 0; i-- {
+		if live[i] || !isSideEffectFree(code[i].Op) {
+			markLive(code[i].Args)
+		} else {
+			code[i].Op = ExRemoved{code[i].Op}
+			removed++
+		}
+	}
+	return removed
+}
+
+// isSideEffectFree checks that `op` doesn't constrain its arguments,
+// therefore it can be removed if its gate isn't used.
+func isSideEffectFree(op Op) bool {
+	switch op {
+	case OpAdd, OpMulAcc, OpNegative, OpSub, OpMul, OpCommit:
+		return true
+	}
+	_, isHint := op.(ExHint)
+	return isHint
+}
+
+// replaceGadgets replaces the calls to the gadgets in `names` with
+// calls to `gadget`, both in `circuits` and in the other gadgets. The
+// values of the IntParams of `gadget` are added to the arguments.
+func (ce *CodeExtractor) replaceGadgets(circuits []*ExCircuit, names map[string]bool, gadget *ExGadget) {
+	replace := func(code []App) {
+		for i, app := range code {
+			if g, ok := app.Op.(*ExGadget); ok && names[g.Name] {
+				code[i].Op = gadget
+				for _, param := range gadget.IntParams {
+					code[i].Args = append(slices.Clone(code[i].Args), Integer{g.ints[param]})
+				}
+			}
+		}
+	}
+	for _, c := range circuits {
+		replace(c.Code)
+	}
+	for _, g := range ce.Gadgets {
+		replace(g.Code)
+	}
+}
+
+// generaliseGadgets replaces the instantiations of each gadget with
+// definitions generic over the sizes of the arguments and over the
+// integer fields. Instantiations which only differ by those are
+// grouped in a single definition,
+// and the ones which can't be grouped keep their own. Gadgets are
+// processed in order of definition, so that the instantiations of a
+// gadget calling a generalised gadget have the same body.
+func (ce *CodeExtractor) generaliseGadgets(circuits []*ExCircuit) {
+	groups := map[reflect.Type][]ExGadget{}
+	types := []reflect.Type{}
+	for _, g := range ce.Gadgets {
+		if _, ok := groups[g.goType]; !ok {
+			types = append(types, g.goType)
+		}
+		groups[g.goType] = append(groups[g.goType], g)
+	}
+
+	generic := map[string]*ExGadget{}
+	for _, t := range types {
+		generalised := 0
+		for _, group := range groupInstances(t.Name(), groups[t]) {
+			if len(group) < 2 {
+				continue
+			}
+			// The first group takes the name of the type, the others
+			// are numbered after it
+			name := t.Name()
+			if generalised > 0 {
+				name = fmt.Sprintf("%s_generic_%d", t.Name(), generalised)
+			}
+			generalised++
+			gadget, _ := generaliseGadget(name, group)
+			names := map[string]bool{}
+			for _, g := range group {
+				names[g.Name] = true
+				generic[g.Name] = gadget
+			}
+			ce.replaceGadgets(circuits, names, gadget)
+		}
+	}
+
+	// The generic gadget takes the place of the first instantiation
+	gadgets := []ExGadget{}
+	added := map[*ExGadget]bool{}
+	for _, g := range ce.Gadgets {
+		gadget, ok := generic[g.Name]
+		if !ok {
+			gadgets = append(gadgets, g)
+		} else if !added[gadget] {
+			added[gadget] = true
+			gadgets = append(gadgets, *gadget)
+		}
+	}
+	ce.Gadgets = gadgets
+}
+
+// groupInstances splits the `instances` of a gadget in groups which can be
+// generalised. Each instance joins the first group it can be generalised
+// with, or starts a new group.
+func groupInstances(name string, instances []ExGadget) [][]ExGadget {
+	groups := [][]ExGadget{}
+	for _, g := range instances {
+		joined := false
+		for i, group := range groups {
+			candidate := append(slices.Clone(group), g)
+			if _, ok := generaliseGadget(name, candidate); ok {
+				groups[i] = candidate
+				joined = true
+				break
+			}
+		}
+		if !joined {
+			groups = append(groups, []ExGadget{g})
+		}
+	}
+	return groups
+}
+
+// generaliseGadget returns a gadget named `name` which is generic over
+// the sizes of the arguments of `instances` and over their integer
+// fields. It fails if the bodies of the instances are different or if
+// they index vectors whose size is a parameter, because Lean can't
+// prove the index is in bounds.
+func generaliseGadget(name string, instances []ExGadget) (*ExGadget, bool) {
+	instances, intParams, ok := integerParams(instances)
+	if !ok {
+		return nil, false
+	}
+	first := instances[0]
+	for _, g := range instances[1:] {
+		if len(g.Code) != len(first.Code) || (len(g.OutputsFlat) == 0) != (len(first.OutputsFlat) == 0) {
+			return nil, false
+		}
+		if genGadgetBody(g.Args, g) != genGadgetBody(first.Args, first) {
+			return nil, false
+		}
+		for i, app := range g.Code {
+			if !sameProjSizes(appOperands(app), appOperands(first.Code[i])) {
+				return nil, false
+			}
+		}
+		if !sameProjSizes(outputOperands(g), outputOperands(first)) {
+			return nil, false
+		}
+	}
+
+	// Sizes which are the same in all the instances aren't parameters.
+	// Sizes which are always equal to each other are the same parameter.
+	sizes := make([][]int, len(instances))
+	for i, g := range instances {
+		sizes[i] = argSizes(g.Args)
+		if len(sizes[i]) != len(sizes[0]) {
+			return nil, false
+		}
+	}
+	params := []string{}
+	paramOf := map[string]string{}
+	names := make([]string, len(sizes[0]))
+	for pos := range names {
+		values := make([]string, len(instances))
+		for i := range instances {
+			values[i] = fmt.Sprintf("%d", sizes[i][pos])
+		}
+		if allEqual(values) {
+			continue
+		}
+		key := strings.Join(values, " ")
+		if _, ok := paramOf[key]; !ok {
+			paramOf[key] = fmt.Sprintf("n_%d", len(params))
+			params = append(params, paramOf[key])
+		}
+		names[pos] = paramOf[key]
+	}
+
+	// The arguments and the outputs must have the same type in all
+	// the instances, once the sizes are replaced by the parameters
+	args := genericArgs(first.Args, names)
+	for _, g := range instances[1:] {
+		if genArgs(genericArgs(g.Args, names)) != genArgs(args) {
+			return nil, false
+		}
+	}
+	outputType := ""
+	if len(first.OutputsFlat) > 0 {
+		outputs := make([][]int, len(instances))
+		for i, g := range instances {
+			outputs[i] = outputSizes(reflect.ValueOf(g.Outputs))
+		}
+		outputNames := make([]string, len(outputs[0]))
+		for pos := range outputNames {
+			values := make([]string, len(instances))
+			for i := range instances {
+				if len(outputs[i]) != len(outputs[0]) {
+					return nil, false
+				}
+				values[i] = fmt.Sprintf("%d", outputs[i][pos])
+			}
+			param, ok := paramOf[strings.Join(values, " ")]
+			switch {
+			case ok:
+				outputNames[pos] = param
+			case allEqual(values):
+				outputNames[pos] = values[0]
+			default:
+				// The size of the output isn't determined by the arguments
+				return nil, false
+			}
+		}
+		outputType = genSizedKTypeSignature(reflect.ValueOf(first.Outputs), outputNames)
+		for _, g := range instances[1:] {
+			if genSizedKTypeSignature(reflect.ValueOf(g.Outputs), outputNames) != outputType {
+				return nil, false
+			}
+		}
+	}
+
+	gadget := first
+	gadget.Name = name
+	gadget.Args = args
+	gadget.Params = params
+	gadget.OutputType = outputType
+	gadget.IntParams = intParams
+	return &gadget, true
+}
+
+// integerParams finds the integer fields with different values in
+// `instances`, which become arguments of type ℕ following the others.
+// In the returned copies of `instances`, the constants equal to the
+// values of those fields are replaced by the new arguments. It fails if
+// a value is negative or if two fields have the same value, because the
+// constants couldn't be attributed to a field.
+func integerParams(instances []ExGadget) ([]ExGadget, []string, bool) {
+	params := []string{}
+	for param := range instances[0].ints {
+		values := make([]string, len(instances))
+		for i, g := range instances {
+			values[i] = g.ints[param].String()
+		}
+		if !allEqual(values) {
+			params = append(params, param)
+		}
+	}
+	if len(params) == 0 {
+		return instances, nil, true
+	}
+	slices.Sort(params)
+
+	res := make([]ExGadget, len(instances))
+	for i, g := range instances {
+		inputs := map[string]Operand{}
+		res[i] = g
+		res[i].Args = slices.Clone(g.Args)
+		for _, param := range params {
+			value := g.ints[param]
+			if _, found := inputs[value.String()]; found || value.Sign() < 0 {
+				return nil, nil, false
+			}
+			inputs[value.String()] = Input{len(res[i].Args)}
+			res[i].Args = append(res[i].Args, ExArg{Name: param, Kind: reflect.Int})
+		}
+		res[i].Code = make([]App, len(g.Code))
+		for j, app := range g.Code {
+			res[i].Code[j] = App{app.Op, make([]Operand, len(app.Args))}
+			for k, arg := range app.Args {
+				res[i].Code[j].Args[k] = replaceIntegers(arg, inputs)
+			}
+		}
+		res[i].OutputsFlat = make([]Operand, len(g.OutputsFlat))
+		for j, output := range g.OutputsFlat {
+			res[i].OutputsFlat[j] = replaceIntegers(output, inputs)
+		}
+	}
+	return res, params, true
+}
+
+// replaceIntegers replaces the Const and Integer in `op` whose value
+// is a key of `inputs` with the corresponding Input
+func replaceIntegers(op Operand, inputs map[string]Operand) Operand {
+	switch op := op.(type) {
+	case Const:
+		if input, ok := inputs[op.Value.String()]; ok {
+			return input
+		}
+		return op
+	case Integer:
+		if input, ok := inputs[op.Value.String()]; ok {
+			return input
+		}
+		return op
+	case Proj:
+		if _, ok := op.Operand.(Const); ok {
+			// Elements of constant vectors aren't integer fields
+			return op
+		}
+		return Proj{replaceIntegers(op.Operand, inputs), op.Index, op.Size}
+	case FieldProj:
+		return FieldProj{replaceIntegers(op.Operand, inputs), op.Name}
+	case ProjArray:
+		projs := make([]Operand, len(op.Projs))
+		for i, proj := range op.Projs {
+			projs[i] = replaceIntegers(proj, inputs)
+		}
+		return ProjArray{projs}
+	case ProjStruct:
+		values := make([]Operand, len(op.Values))
+		for i, value := range op.Values {
+			values[i] = replaceIntegers(value, inputs)
+		}
+		return ProjStruct{op.Fields, values}
+	default:
+		return op
+	}
+}
+
+// appOperands returns the arguments of `app` as they are exported to Lean
+func appOperands(app App) []Operand {
+	if app.Op == OpFromBinary || app.Op == OpCommit {
+		return []Operand{ProjArray{app.Args}}
+	}
+	return app.Args
+}
+
+// outputOperands returns the outputs of `gadget` as they are exported to Lean
+func outputOperands(gadget ExGadget) []Operand {
+	if hasVectorOutputs(gadget) {
+		return []Operand{ProjArray{gadget.OutputsFlat}}
+	}
+	return gadget.OutputsFlat
+}
+
+// allEqual checks that all the `values` are the same
+func allEqual(values []string) bool {
+	for _, v := range values {
+		if v != values[0] {
+			return false
+		}
+	}
+	return true
+}
+
+// argSizes returns the sizes of the vectors in `args`, outermost first
+func argSizes(args []ExArg) []int {
+	sizes := []int{}
+	for _, arg := range args {
+		if arg.Kind != reflect.Array && arg.Kind != reflect.Slice {
+			continue
+		}
+		for t := &arg.Type; t != nil; t = t.Type {
+			sizes = append(sizes, t.Size)
+		}
+	}
+	return sizes
+}
+
+// genericArgs returns a copy of `args` where the sizes returned by
+// argSizes are replaced by the parameters in `names`, if not empty.
+func genericArgs(args []ExArg, names []string) []ExArg {
+	res := make([]ExArg, len(args))
+	pos := 0
+	for i, arg := range args {
+		res[i] = arg
+		if arg.Kind != reflect.Array && arg.Kind != reflect.Slice {
+			continue
+		}
+		res[i].Type = arg.Type
+		for t := &res[i].Type; t != nil; t = t.Type {
+			t.Param = names[pos]
+			pos++
+			if t.Type != nil {
+				inner := *t.Type
+				t.Type = &inner
+			}
+		}
+	}
+	return res
+}
+
+// outputSizes returns the sizes of the nested vectors in `output`
+func outputSizes(output reflect.Value) []int {
+	sizes := []int{}
+	for {
+		if output.Kind() == reflect.Interface && !output.IsNil() {
+			output = output.Elem()
+		}
+		if output.Kind() != reflect.Slice && output.Kind() != reflect.Array {
+			return sizes
+		}
+		sizes = append(sizes, output.Len())
+		if output.Len() == 0 {
+			return sizes
+		}
+		output = output.Index(0)
+	}
+}
+
+// sameProjSizes checks that the Proj in `a` and `b` have the same Size.
+// It's used to detect the operands indexing vectors of different sizes.
+func sameProjSizes(a []Operand, b []Operand) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		switch op := a[i].(type) {
+		case Proj:
+			other, ok := b[i].(Proj)
+			if !ok || op.Size != other.Size || !sameProjSizes([]Operand{op.Operand}, []Operand{other.Operand}) {
+				return false
+			}
+		case FieldProj:
+			other, ok := b[i].(FieldProj)
+			if !ok || !sameProjSizes([]Operand{op.Operand}, []Operand{other.Operand}) {
+				return false
+			}
+		case ProjArray:
+			other, ok := b[i].(ProjArray)
+			if !ok {
+				return false
+			}
+			// Complete vectors are exported with the name of the vector
+			isComplete, simplified := isVectorComplete(op)
+			otherIsComplete, otherSimplified := isVectorComplete(other)
+			if isComplete != otherIsComplete {
+				return false
+			}
+			if isComplete {
+				if !sameProjSizes([]Operand{simplified}, []Operand{otherSimplified}) {
+					return false
+				}
+			} else if !sameProjSizes(op.Projs, other.Projs) {
+				return false
+			}
+		case ProjStruct:
+			other, ok := b[i].(ProjStruct)
+			if !ok || !sameProjSizes(op.Values, other.Values) {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,53 @@
+// This file contains the targets of the extraction, which are the
+// versions of ProvenZK the Lean code is written for.
+package extractor
+
+import "golang.org/x/exp/slices"
+
+// GatesVariant is the name of a ProvenZK structure defining the semantics
+// of the gates, which changes with the version of gnark
+type GatesVariant string
+
+const (
+	// GatesGnark8 are the gates of gnark v0.8
+	GatesGnark8 GatesVariant = "GatesGnark8"
+	// GatesGnark9 are the gates of gnark v0.9
+	GatesGnark9 GatesVariant = "GatesGnark9"
+)
+
+// Target is the prelude of the Lean code for a version of ProvenZK.
+// Targets for other releases can be built from the ones below by
+// changing the fields which differ.
+type Target struct {
+	// Imports are the Lean modules of ProvenZK used by the extracted code
+	Imports []string
+	// SetOptions are the `set_option` directives of the header
+	SetOptions []string
+	// Gates is the variant of the gates the circuits are exported with
+	Gates GatesVariant
+}
+
+// ProvenZK14 targets ProvenZK v1.4.0 with the gates of gnark v0.9,
+// which is the default target
+func ProvenZK14() Target {
+	return Target{
+		Imports:    []string{"ProvenZk.Gates", "ProvenZk.Ext.Vector"},
+		SetOptions: []string{"linter.unusedVariables false"},
+		Gates:      GatesGnark9,
+	}
+}
+
+// ProvenZK14Gnark8 targets ProvenZK v1.4.0 with the gates of gnark v0.8,
+// for the circuits whose semantics in Lean must match gnark v0.8
+func ProvenZK14Gnark8() Target {
+	target := ProvenZK14()
+	target.Gates = GatesGnark8
+	return target
+}
+
+// clone returns a copy of `t` which doesn't share its slices
+func (t Target) clone() Target {
+	t.Imports = slices.Clone(t.Imports)
+	t.SetOptions = slices.Clone(t.SetOptions)
+	return t
+}
@@ -0,0 +1,97 @@
+package extractor_test
+
+import (
+	"log"
+	"strings"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/lookup/logderivlookup"
+	"github.com/reilabs/gnark-lean-extractor/v3/extractor"
+	"github.com/stretchr/testify/assert"
+)
+
+// Example: circuit using gnark blueprints directly
+type BlueprintCircuit struct {
+	Entries [3]frontend.Variable
+	Index   frontend.Variable
+	Out     frontend.Variable
+}
+
+func (circuit *BlueprintCircuit) Define(api frontend.API) error {
+	compiler := api.Compiler()
+
+	var lookup constraint.BlueprintLookupHint
+	lookupID := compiler.AddBlueprint(&lookup)
+	for _, e := range circuit.Entries {
+		compiler.ToCanonicalVariable(e).Compress(&lookup.EntriesCalldata)
+	}
+	calldata := []uint32{0, uint32(len(circuit.Entries)), 1}
+	compiler.ToCanonicalVariable(circuit.Index).Compress(&calldata)
+	calldata[0] = uint32(len(calldata))
+	outputs := compiler.AddInstruction(lookupID, calldata)
+	value := compiler.InternalVariable(outputs[0])
+
+	// value * Out == Entries[0]
+	r1cID := compiler.AddBlueprint(&constraint.BlueprintGenericR1C{})
+	var r1c []uint32
+	(&constraint.BlueprintGenericR1C{}).CompressR1C(&constraint.R1C{
+		L: constraint.LinearExpression{compiler.ToCanonicalVariable(value).(constraint.Term)},
+		R: constraint.LinearExpression{compiler.ToCanonicalVariable(circuit.Out).(constraint.Term)},
+		O: constraint.LinearExpression{compiler.ToCanonicalVariable(circuit.Entries[0]).(constraint.Term)},
+	}, &r1c)
+	compiler.AddInstruction(r1cID, r1c)
+
+	// Entries[1] * Entries[2] == product
+	mulID := compiler.AddBlueprint(&constraint.BlueprintSparseR1CMul{})
+	var mul []uint32
+	productWire := uint32(1000)
+	(&constraint.BlueprintSparseR1CMul{}).CompressSparseR1C(&constraint.SparseR1C{
+		XA: compiler.ToCanonicalVariable(circuit.Entries[1]).(constraint.Term).VID,
+		XB: compiler.ToCanonicalVariable(circuit.Entries[2]).(constraint.Term).VID,
+		XC: productWire,
+		QM: constraint.CoeffIdOne,
+	}, &mul)
+	compiler.AddInstruction(mulID, mul)
+	api.AssertIsEqual(compiler.InternalVariable(productWire), circuit.Out)
+	return nil
+}
+
+func TestBlueprintCircuit(t *testing.T) {
+	assignment := BlueprintCircuit{}
+	out, err := extractor.CircuitToLean(&assignment, ecc.BN254)
+	if err != nil {
+		log.Fatal(err)
+	}
+	checkOutput(t, out)
+}
+
+// Example: lookup table from gnark std library
+type LookupCircuit struct {
+	Entries [4]frontend.Variable
+	Queries [2]frontend.Variable
+	Results [2]frontend.Variable
+}
+
+func (circuit *LookupCircuit) Define(api frontend.API) error {
+	table := logderivlookup.New(api)
+	for _, e := range circuit.Entries {
+		table.Insert(e)
+	}
+	results := table.Lookup(circuit.Queries[:]...)
+	for i := range results {
+		api.AssertIsEqual(results[i], circuit.Results[i])
+	}
+	return nil
+}
+
+func TestLookupCircuit(t *testing.T) {
+	assignment := LookupCircuit{}
+	out, err := extractor.CircuitToLean(&assignment, ecc.BN254)
+	assert.NoError(t, err)
+	assert.Contains(t, out, "-- hint constraint.BlueprintLookupHint")
+	assert.Contains(t, out, "GatesExt.commit")
+	assert.True(t, strings.HasSuffix(out, "end LookupCircuit"))
+}
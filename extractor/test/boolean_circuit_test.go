@@ -0,0 +1,85 @@
+package extractor_test
+
+import (
+	"log"
+	"strings"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/reilabs/gnark-lean-extractor/v3/abstractor"
+	"github.com/reilabs/gnark-lean-extractor/v3/extractor"
+	"github.com/stretchr/testify/assert"
+)
+
+// Example: gadget skipping redundant booleanity constraints
+type BoolSelect struct {
+	Cond frontend.Variable
+	A    frontend.Variable
+	B    frontend.Variable
+}
+
+func (gadget BoolSelect) DefineGadget(api frontend.API) interface{} {
+	if !api.Compiler().IsBoolean(gadget.Cond) {
+		api.AssertIsBoolean(gadget.Cond)
+	}
+	if !api.Compiler().IsBoolean(gadget.Cond) {
+		panic("Cond must be boolean after the assertion")
+	}
+	return api.Add(api.Mul(gadget.Cond, api.Sub(gadget.A, gadget.B)), gadget.B)
+}
+
+type BooleanCircuit struct {
+	In   frontend.Variable
+	Flag frontend.Variable
+	Out  frontend.Variable
+}
+
+func (circuit *BooleanCircuit) Define(api frontend.API) error {
+	bits := api.ToBinary(circuit.In, 2)
+	isZero := api.IsZero(circuit.In)
+	xor := api.Xor(bits[0], bits[1])
+	for _, b := range []frontend.Variable{bits[0], bits[1], isZero, xor, 0, 1} {
+		if !api.Compiler().IsBoolean(b) {
+			panic("expected boolean")
+		}
+	}
+	if api.Compiler().IsBoolean(circuit.Flag) || api.Compiler().IsBoolean(2) {
+		panic("unexpected boolean")
+	}
+	api.Compiler().MarkBoolean(circuit.Flag)
+	api.Compiler().MarkBoolean(xor)
+
+	if !api.Compiler().IsBoolean(circuit.Flag) {
+		panic("Flag has been marked as boolean")
+	}
+	api.AssertIsBoolean(circuit.Flag)
+	res := abstractor.Call(api, BoolSelect{circuit.Flag, isZero, xor})
+	api.AssertIsEqual(res, circuit.Out)
+	return nil
+}
+
+func TestBooleanCircuit(t *testing.T) {
+	assignment := BooleanCircuit{}
+	out, err := extractor.CircuitToLean(&assignment, ecc.BN254)
+	if err != nil {
+		log.Fatal(err)
+	}
+	checkOutput(t, out)
+}
+
+func TestBooleanCircuitFacts(t *testing.T) {
+	assignment := BooleanCircuit{}
+	out, err := extractor.CircuitToLean(&assignment, ecc.BN254, extractor.WithBooleanFacts())
+	if err != nil {
+		log.Fatal(err)
+	}
+	checkOutput(t, out)
+
+	withoutFacts, err := extractor.CircuitToLean(&assignment, ecc.BN254)
+	if err != nil {
+		log.Fatal(err)
+	}
+	assert.Equal(t, 1, strings.Count(out, "Gates.is_bool Flag"), "AssertIsBoolean must be skipped after MarkBoolean")
+	assert.Equal(t, 1, strings.Count(withoutFacts, "Gates.is_bool Flag"), "AssertIsBoolean must not be skipped after MarkBoolean")
+}
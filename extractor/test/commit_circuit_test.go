@@ -0,0 +1,50 @@
+package extractor_test
+
+import (
+	"log"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/reilabs/gnark-lean-extractor/v3/extractor"
+)
+
+// Example: circuit with Fiat-Shamir commitment
+type CommitCircuit struct {
+	In       []frontend.Variable
+	Expected frontend.Variable
+}
+
+func (circuit *CommitCircuit) Define(api frontend.API) error {
+	committer, ok := api.(frontend.Committer)
+	if !ok {
+		panic("api doesn't implement frontend.Committer")
+	}
+	challenge, err := committer.Commit(circuit.In...)
+	if err != nil {
+		return err
+	}
+
+	// Evaluate the polynomial with coefficients `In` at `challenge`
+	res := frontend.Variable(0)
+	for i := len(circuit.In) - 1; i >= 0; i-- {
+		res = api.Add(api.Mul(res, challenge), circuit.In[i])
+	}
+	api.AssertIsEqual(res, circuit.Expected)
+
+	second, err := committer.Commit(challenge, circuit.In[0], circuit.Expected)
+	if err != nil {
+		return err
+	}
+	api.AssertIsDifferent(second, 0)
+	return nil
+}
+
+func TestCommitCircuit(t *testing.T) {
+	assignment := CommitCircuit{In: make([]frontend.Variable, 3)}
+	out, err := extractor.CircuitToLean(&assignment, ecc.BN254)
+	if err != nil {
+		log.Fatal(err)
+	}
+	checkOutput(t, out)
+}
@@ -0,0 +1,48 @@
+package extractor_test
+
+import (
+	"log"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/test"
+	"github.com/reilabs/gnark-lean-extractor/v3/abstractor"
+	"github.com/reilabs/gnark-lean-extractor/v3/extractor"
+	"github.com/stretchr/testify/assert"
+)
+
+// Example: circuit defined once for both gnark and the extractor
+type ConcreteCircuit struct {
+	In_1 frontend.Variable
+	In_2 frontend.Variable
+	Out  frontend.Variable
+}
+
+func (circuit *ConcreteCircuit) AbsDefine(api abstractor.API) error {
+	hash := api.Call(DummyHash{circuit.In_1, circuit.In_2})
+	sum := api.Add(hash, circuit.In_1)
+	api.AssertIsEqual(sum, circuit.Out)
+	return nil
+}
+
+func (circuit *ConcreteCircuit) Define(api frontend.API) error {
+	return abstractor.Concretize(api, circuit)
+}
+
+func TestConcreteCircuit(t *testing.T) {
+	circuit := ConcreteCircuit{}
+	out, err := extractor.CircuitToLean(&circuit, ecc.BN254)
+	if err != nil {
+		log.Fatal(err)
+	}
+	checkOutput(t, out)
+
+	_, err = frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &ConcreteCircuit{})
+	assert.NoError(t, err)
+
+	assignment := ConcreteCircuit{In_1: 3, In_2: 5, Out: 18}
+	err = test.IsSolved(&ConcreteCircuit{}, &assignment, ecc.BN254.ScalarField())
+	assert.NoError(t, err)
+}
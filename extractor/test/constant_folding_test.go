@@ -0,0 +1,48 @@
+package extractor_test
+
+import (
+	"log"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/reilabs/gnark-lean-extractor/v3/extractor"
+)
+
+// Example: arithmetic on constants is folded, and the folded
+// values are seen by ConstantValue
+type ConstantFoldingCircuit struct {
+	In  frontend.Variable
+	Out frontend.Variable
+}
+
+func (circuit *ConstantFoldingCircuit) Define(api frontend.API) error {
+	scale := api.Mul(api.Add(1, 2, 3), 7)
+	half := api.Div(1, 2)
+	minusOne := api.Neg(1)
+	// Folded values are reduced modulo the order of the field
+	wrapped := api.Sub(api.Inverse(minusOne), -1)
+	zero := api.MulAcc(wrapped, scale, 0)
+	if v, ok := api.Compiler().ConstantValue(zero); !ok || v.Sign() != 0 {
+		panic("constant not folded")
+	}
+	res := api.MulAcc(api.Mul(circuit.In, scale), half, minusOne)
+	api.AssertIsEqual(res, circuit.Out)
+	// The constants of variadic operations are folded together
+	// and the neutral elements are dropped
+	api.AssertIsEqual(api.Add(circuit.In, 1, circuit.Out, 2), api.Mul(2, circuit.In, 3, circuit.Out))
+	api.AssertIsEqual(api.Add(circuit.In, 1, -1), api.Sub(circuit.Out, 4, -4))
+	if v, ok := api.Compiler().ConstantValue(api.Mul(circuit.In, 0, circuit.Out)); !ok || v.Sign() != 0 {
+		panic("product by zero not folded")
+	}
+	return nil
+}
+
+func TestConstantFoldingCircuit(t *testing.T) {
+	assignment := ConstantFoldingCircuit{}
+	out, err := extractor.CircuitToLean(&assignment, ecc.BN254)
+	if err != nil {
+		log.Fatal(err)
+	}
+	checkOutput(t, out)
+}
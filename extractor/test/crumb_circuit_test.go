@@ -0,0 +1,35 @@
+package extractor_test
+
+import (
+	"log"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/reilabs/gnark-lean-extractor/v3/extractor"
+)
+
+// Example: decomposition of a value in base 4
+type CrumbCircuit struct {
+	In     frontend.Variable
+	Crumbs [3]frontend.Variable
+}
+
+func (circuit *CrumbCircuit) Define(api frontend.API) error {
+	sum := frontend.Variable(0)
+	for i := len(circuit.Crumbs) - 1; i >= 0; i-- {
+		api.AssertIsCrumb(circuit.Crumbs[i])
+		sum = api.MulAcc(circuit.Crumbs[i], sum, 4)
+	}
+	api.AssertIsEqual(sum, circuit.In)
+	return nil
+}
+
+func TestCrumbCircuit(t *testing.T) {
+	assignment := CrumbCircuit{}
+	out, err := extractor.CircuitToLean(&assignment, ecc.BN254)
+	if err != nil {
+		log.Fatal(err)
+	}
+	checkOutput(t, out)
+}
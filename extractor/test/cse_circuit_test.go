@@ -0,0 +1,53 @@
+package extractor_test
+
+import (
+	"log"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/reilabs/gnark-lean-extractor/v3/abstractor"
+	"github.com/reilabs/gnark-lean-extractor/v3/extractor"
+)
+
+// Example: expressions and gadget calls repeated with the same
+// arguments are exported once
+type SquareSum struct {
+	A frontend.Variable
+	B frontend.Variable
+}
+
+func (gadget SquareSum) DefineGadget(api frontend.API) interface{} {
+	// The second square of A is the same gate as the first one
+	return api.Add(api.Mul(gadget.A, gadget.A), api.Mul(gadget.B, gadget.B), api.Mul(gadget.A, gadget.A))
+}
+
+type CseCircuit struct {
+	Path     [2]frontend.Variable
+	Dividend frontend.Variable
+	Divisor  frontend.Variable
+}
+
+func (circuit *CseCircuit) Define(api frontend.API) error {
+	first := api.Add(api.Mul(circuit.Path[0], circuit.Path[0]), circuit.Path[1])
+	second := api.Add(api.Mul(circuit.Path[0], circuit.Path[0]), circuit.Path[1])
+	api.AssertIsEqual(first, second)
+
+	sum := abstractor.Call(api, SquareSum{circuit.Path[0], circuit.Path[1]})
+	api.AssertIsEqual(sum, abstractor.Call(api, SquareSum{circuit.Path[0], circuit.Path[1]}))
+	api.AssertIsEqual(sum, abstractor.Call(api, SquareSum{circuit.Path[1], circuit.Path[0]}))
+
+	// Gadgets using hints are called again
+	res := abstractor.Call1(api, DivMod{circuit.Dividend, circuit.Divisor})
+	api.AssertIsEqual(res[0], abstractor.Call1(api, DivMod{circuit.Dividend, circuit.Divisor})[0])
+	return nil
+}
+
+func TestCseCircuit(t *testing.T) {
+	assignment := CseCircuit{}
+	out, err := extractor.CircuitToLean(&assignment, ecc.BN254, extractor.WithCommonSubexpressions())
+	if err != nil {
+		log.Fatal(err)
+	}
+	checkOutput(t, out)
+}
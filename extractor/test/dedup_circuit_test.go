@@ -0,0 +1,149 @@
+package extractor_test
+
+import (
+	"errors"
+	"log"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/reilabs/gnark-lean-extractor/v3/abstractor"
+	"github.com/reilabs/gnark-lean-extractor/v3/extractor"
+)
+
+// Example: gadgets are deduplicated by their body, not by the
+// values of the fields used to name them
+type Rounded struct {
+	In    frontend.Variable
+	Scale float64
+	// Rounds doesn't change the body
+	Rounds int
+}
+
+func (gadget Rounded) DefineGadget(api frontend.API) frontend.Variable {
+	if gadget.Scale < 1 {
+		return api.Mul(gadget.In, 2)
+	}
+	return api.Mul(gadget.In, 3)
+}
+
+type DedupCircuit struct {
+	In frontend.Variable
+}
+
+func (circuit *DedupCircuit) Define(api frontend.API) error {
+	a := abstractor.CallT[frontend.Variable](api, Rounded{circuit.In, 0.5, 1})
+	b := abstractor.CallT[frontend.Variable](api, Rounded{circuit.In, 0.7, 2})
+	c := abstractor.CallT[frontend.Variable](api, Rounded{circuit.In, 1.5, 3})
+	api.AssertIsEqual(api.Add(a, b), c)
+	return nil
+}
+
+func TestDedupCircuit(t *testing.T) {
+	assignment := DedupCircuit{}
+	out, err := extractor.CircuitToLean(&assignment, ecc.BN254)
+	if err != nil {
+		log.Fatal(err)
+	}
+	checkOutput(t, out)
+}
+
+// Example: nested gadgets called many times, whose bodies are only
+// defined once
+var leafDefinitions, midDefinitions int
+
+type Leaf struct {
+	In frontend.Variable
+}
+
+func (gadget Leaf) DefineGadget(api frontend.API) frontend.Variable {
+	leafDefinitions++
+	return api.Mul(gadget.In, gadget.In)
+}
+
+type Mid struct {
+	In frontend.Variable
+}
+
+func (gadget Mid) DefineGadget(api frontend.API) frontend.Variable {
+	midDefinitions++
+	res := gadget.In
+	for i := 0; i < 10; i++ {
+		res = abstractor.CallT[frontend.Variable](api, Leaf{res})
+	}
+	return res
+}
+
+type NestedCallsCircuit struct {
+	In frontend.Variable
+}
+
+func (circuit *NestedCallsCircuit) Define(api frontend.API) error {
+	res := circuit.In
+	for i := 0; i < 10; i++ {
+		res = abstractor.CallT[frontend.Variable](api, Mid{res})
+		// Same definition as Rounded_1, reported once
+		abstractor.CallT[frontend.Variable](api, Rounded{res, 0.5, 1})
+		abstractor.CallT[frontend.Variable](api, Rounded{res, 0.7, 2})
+	}
+	api.AssertIsEqual(res, 1)
+	return nil
+}
+
+func TestNestedCallsDefinedOnce(t *testing.T) {
+	leafDefinitions, midDefinitions = 0, 0
+	assignment := NestedCallsCircuit{}
+	diagnostics := extractor.Diagnostics{}
+	_, err := extractor.CircuitToLean(&assignment, ecc.BN254, extractor.WithDiagnostics(&diagnostics))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if leafDefinitions != 1 || midDefinitions != 1 {
+		t.Fatalf("expected each gadget to be defined once, got Leaf %d times and Mid %d times", leafDefinitions, midDefinitions)
+	}
+	if len(diagnostics) != 1 {
+		t.Fatalf("expected 1 diagnostic, got %v", diagnostics)
+	}
+}
+
+// Example: gadget configured by a float in a nested struct, which isn't
+// part of the name of the gadget
+type ScaleConfig struct {
+	Factor float64
+}
+
+type ScaleNested struct {
+	In  frontend.Variable
+	Cfg ScaleConfig
+}
+
+func (gadget ScaleNested) DefineGadget(api frontend.API) frontend.Variable {
+	if gadget.Cfg.Factor < 1 {
+		return api.Mul(gadget.In, 2)
+	}
+	return api.Mul(gadget.In, 3)
+}
+
+type NestedConfigCircuit struct {
+	In      frontend.Variable
+	Factors []float64
+}
+
+func (circuit *NestedConfigCircuit) Define(api frontend.API) error {
+	for _, factor := range circuit.Factors {
+		abstractor.CallT[frontend.Variable](api, ScaleNested{circuit.In, ScaleConfig{factor}})
+	}
+	return nil
+}
+
+func TestNestedConfigCircuit(t *testing.T) {
+	_, err := extractor.CircuitToLean(&NestedConfigCircuit{Factors: []float64{0.5, 0.5}}, ecc.BN254)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The body for 1.5 is different, not the cached body for 0.5
+	_, err = extractor.CircuitToLean(&NestedConfigCircuit{Factors: []float64{0.5, 1.5}}, ecc.BN254)
+	if !errors.Is(err, extractor.ErrGadgetConflict) {
+		t.Fatalf("expected ErrGadgetConflict, got %v", err)
+	}
+}
@@ -0,0 +1,53 @@
+package extractor_test
+
+import (
+	"log"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/multicommit"
+	"github.com/reilabs/gnark-lean-extractor/v3/abstractor"
+	"github.com/reilabs/gnark-lean-extractor/v3/extractor"
+)
+
+// Example: deferred constraints registered by gnark std gadgets
+type ChallengeGadget struct {
+	In []frontend.Variable
+}
+
+func (gadget ChallengeGadget) DefineGadget(api frontend.API) interface{} {
+	multicommit.WithCommitment(api, func(api frontend.API, commitment frontend.Variable) error {
+		api.AssertIsDifferent(api.Sub(commitment, gadget.In[0]), gadget.In[1])
+		return nil
+	}, gadget.In...)
+	return api.Add(gadget.In[0], gadget.In[1])
+}
+
+type DeferCircuit struct {
+	In  []frontend.Variable
+	Out frontend.Variable
+}
+
+func (circuit *DeferCircuit) Define(api frontend.API) error {
+	multicommit.WithCommitment(api, func(api frontend.API, commitment frontend.Variable) error {
+		api.AssertIsDifferent(commitment, circuit.Out)
+		return nil
+	}, circuit.In[0], circuit.Out)
+	api.Compiler().Defer(func(api frontend.API) error {
+		api.AssertIsEqual(circuit.In[1], circuit.Out)
+		return nil
+	})
+	sum := abstractor.Call(api, ChallengeGadget{circuit.In})
+	api.AssertIsEqual(sum, circuit.Out)
+	return nil
+}
+
+func TestDeferCircuit(t *testing.T) {
+	assignment := DeferCircuit{In: make([]frontend.Variable, 2)}
+	out, err := extractor.CircuitToLean(&assignment, ecc.BN254)
+	if err != nil {
+		log.Fatal(err)
+	}
+	checkOutput(t, out)
+}
@@ -0,0 +1,54 @@
+package extractor_test
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/reilabs/gnark-lean-extractor/v3/extractor"
+)
+
+func TestDiagnostics(t *testing.T) {
+	assignment := DedupCircuit{}
+	diagnostics := extractor.Diagnostics{}
+	_, err := extractor.CircuitToLean(&assignment, ecc.BN254, extractor.WithDiagnostics(&diagnostics), extractor.WithStrict())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diagnostics.Warnings()) != 0 {
+		t.Fatalf("unexpected warnings %v", diagnostics.Warnings())
+	}
+	infos := diagnostics.Infos()
+	if len(infos) != 1 {
+		t.Fatalf("expected 1 info, got %v", infos)
+	}
+	if infos[0].Gadget != "Rounded_2" || !strings.Contains(infos[0].Message, "Rounded_1") {
+		t.Fatalf("unexpected info %v", infos[0])
+	}
+}
+
+func TestDiagnosticsSlogHandler(t *testing.T) {
+	assignment := TwoGadgets{Num: 11}
+	buf := bytes.Buffer{}
+	handler := slog.NewTextHandler(&buf, nil)
+	_, err := extractor.CircuitToLean(&assignment, ecc.BN254, extractor.WithDeadGateElimination(), extractor.WithSlogHandler(handler))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "level=INFO msg=\"removed") {
+		t.Fatalf("unexpected log %q", buf.String())
+	}
+}
+
+func TestDiagnosticsReturned(t *testing.T) {
+	assignment := DedupCircuit{}
+	diagnostics, err := extractor.Extract(&assignment, extractor.WithOutput(&bytes.Buffer{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diagnostics.Infos()) != 1 || diagnostics.Infos()[0].Gadget != "Rounded_2" {
+		t.Fatalf("unexpected diagnostics %v", diagnostics)
+	}
+}
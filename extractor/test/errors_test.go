@@ -0,0 +1,174 @@
+package extractor_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/frontend"
+	"github.com/reilabs/gnark-lean-extractor/v3/abstractor"
+	"github.com/reilabs/gnark-lean-extractor/v3/extractor"
+)
+
+// Example: gadget calling a gadget which uses the API incorrectly
+type NegativeBits struct {
+	In frontend.Variable
+}
+
+func (gadget NegativeBits) DefineGadget(api frontend.API) interface{} {
+	return api.ToBinary(gadget.In, -1)
+}
+
+type CallNegativeBits struct {
+	In frontend.Variable
+}
+
+func (gadget CallNegativeBits) DefineGadget(api frontend.API) interface{} {
+	return abstractor.Call1(api, NegativeBits{gadget.In})
+}
+
+type InvalidCircuit struct {
+	In frontend.Variable
+}
+
+func (circuit *InvalidCircuit) Define(api frontend.API) error {
+	abstractor.Call1(api, CallNegativeBits{circuit.In})
+	return nil
+}
+
+type UnsupportedOperandCircuit struct {
+	In frontend.Variable
+}
+
+func (circuit *UnsupportedOperandCircuit) Define(api frontend.API) error {
+	api.AssertIsEqual(circuit.In, 1.5)
+	return nil
+}
+
+type ConflictCircuit struct {
+	In frontend.Variable
+}
+
+func (circuit *ConflictCircuit) Define(api frontend.API) error {
+	// Same name, different bodies
+	abstractor.CallT[frontend.Variable](api, Rounded{circuit.In, 0.5, 1})
+	abstractor.CallT[frontend.Variable](api, Rounded{circuit.In, 1.5, 1})
+	return nil
+}
+
+// UnknownWireCircuit reads a wire which isn't the output of any instruction
+type UnknownWireCircuit struct {
+	In frontend.Variable
+}
+
+func (circuit *UnknownWireCircuit) Define(api frontend.API) error {
+	api.AssertIsEqual(api.Compiler().InternalVariable(1000), circuit.In)
+	return nil
+}
+
+// UnknownInputWireCircuit uses an unassigned wire as input of a SparseR1C
+type UnknownInputWireCircuit struct {
+	In frontend.Variable
+}
+
+func (circuit *UnknownInputWireCircuit) Define(api frontend.API) error {
+	compiler := api.Compiler()
+	mulID := compiler.AddBlueprint(&constraint.BlueprintSparseR1CMul{})
+	var mul []uint32
+	(&constraint.BlueprintSparseR1CMul{}).CompressSparseR1C(&constraint.SparseR1C{
+		XA: compiler.ToCanonicalVariable(circuit.In).(constraint.Term).VID,
+		XB: 1000,
+		XC: 1001,
+		QM: constraint.CoeffIdOne,
+	}, &mul)
+	compiler.AddInstruction(mulID, mul)
+	return nil
+}
+
+var errDefine = errors.New("define failed")
+
+type FailingCircuit struct {
+	In frontend.Variable
+}
+
+func (circuit *FailingCircuit) Define(api frontend.API) error {
+	return errDefine
+}
+
+func TestErrorGadgetStack(t *testing.T) {
+	out, err := extractor.CircuitToLean(&InvalidCircuit{}, ecc.BN254)
+	if out != "" || !errors.Is(err, extractor.ErrInvalidArgument) {
+		t.Fatalf("expected ErrInvalidArgument, got %v", err)
+	}
+	var extractionErr *extractor.ExtractionError
+	if !errors.As(err, &extractionErr) {
+		t.Fatalf("expected ExtractionError, got %T", err)
+	}
+	if len(extractionErr.Gadgets) != 2 {
+		t.Fatalf("expected 2 gadgets in the stack, got %+v", extractionErr.Gadgets)
+	}
+	for i, name := range []string{"CallNegativeBits", "NegativeBits"} {
+		frame := extractionErr.Gadgets[i]
+		if frame.Name != name || !strings.Contains(frame.Location, "errors_test.go") {
+			t.Errorf("unexpected gadget frame %+v", frame)
+		}
+	}
+	if extractionErr.Operand != -1 {
+		t.Errorf("unexpected operand %v", extractionErr.Operand)
+	}
+}
+
+func TestErrorUnknownWire(t *testing.T) {
+	for _, circuit := range []frontend.Circuit{&UnknownWireCircuit{}, &UnknownInputWireCircuit{}} {
+		_, err := extractor.CircuitToLean(circuit, ecc.BN254)
+		if !errors.Is(err, extractor.ErrInvalidArgument) {
+			t.Fatalf("expected ErrInvalidArgument for %T, got %v", circuit, err)
+		}
+		var extractionErr *extractor.ExtractionError
+		if !errors.As(err, &extractionErr) || extractionErr.Operand != uint32(1000) {
+			t.Fatalf("unexpected error %#v", err)
+		}
+	}
+}
+
+func TestErrorUnsupportedType(t *testing.T) {
+	_, err := extractor.CircuitToLean(&UnsupportedOperandCircuit{}, ecc.BN254)
+	if !errors.Is(err, extractor.ErrUnsupportedType) {
+		t.Fatalf("expected ErrUnsupportedType, got %v", err)
+	}
+	var extractionErr *extractor.ExtractionError
+	if !errors.As(err, &extractionErr) || extractionErr.Operand != 1.5 {
+		t.Fatalf("unexpected error %#v", err)
+	}
+}
+
+func TestErrorInvalidNamespace(t *testing.T) {
+	_, err := extractor.CircuitToLeanWithName(&MerkleRecover{}, ecc.BN254, "Merkle Recover")
+	if !errors.Is(err, extractor.ErrInvalidNamespace) {
+		t.Fatalf("expected ErrInvalidNamespace, got %v", err)
+	}
+	_, err = extractor.GadgetToLeanWithName(&DummyHash{}, ecc.BN254, "Dummy Hash")
+	if !errors.Is(err, extractor.ErrInvalidNamespace) {
+		t.Fatalf("expected ErrInvalidNamespace, got %v", err)
+	}
+}
+
+func TestErrorGadgetConflict(t *testing.T) {
+	_, err := extractor.CircuitToLean(&ConflictCircuit{}, ecc.BN254)
+	if !errors.Is(err, extractor.ErrGadgetConflict) {
+		t.Fatalf("expected ErrGadgetConflict, got %v", err)
+	}
+}
+
+func TestErrorDefine(t *testing.T) {
+	_, err := extractor.CircuitToLean(&FailingCircuit{}, ecc.BN254)
+	var extractionErr *extractor.ExtractionError
+	if !errors.As(err, &extractionErr) || extractionErr.Cause != errDefine {
+		t.Fatalf("expected ExtractionError caused by errDefine, got %#v", err)
+	}
+	if !errors.Is(err, errDefine) {
+		t.Fatalf("expected errDefine, got %v", err)
+	}
+}
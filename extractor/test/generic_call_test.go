@@ -0,0 +1,125 @@
+package extractor_test
+
+import (
+	"log"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/reilabs/gnark-lean-extractor/v3/abstractor"
+	"github.com/reilabs/gnark-lean-extractor/v3/extractor"
+	"github.com/stretchr/testify/assert"
+)
+
+// Example: gadget returning a 4-dimensional slice called with CallT
+type Spread struct {
+	In [2]frontend.Variable
+}
+
+func (gadget Spread) DefineGadget(api frontend.API) [][][][]frontend.Variable {
+	res := make([][][][]frontend.Variable, 2)
+	for i := range res {
+		res[i] = [][][]frontend.Variable{{{
+			api.Mul(gadget.In[0], i+1),
+			api.Mul(gadget.In[1], i+1),
+		}}}
+	}
+	return res
+}
+
+type GenericCallCircuit struct {
+	In  [2]frontend.Variable
+	Out frontend.Variable
+}
+
+func (circuit *GenericCallCircuit) AbsDefine(api abstractor.API) error {
+	spread := abstractor.CallT[[][][][]frontend.Variable](api, Spread{circuit.In})
+	api.AssertIsEqual(spread[1][0][0][1], circuit.Out)
+	return nil
+}
+
+func (circuit *GenericCallCircuit) Define(api frontend.API) error {
+	return abstractor.Concretize(api, circuit)
+}
+
+func TestGenericCallCircuit(t *testing.T) {
+	circuit := GenericCallCircuit{}
+	out, err := extractor.CircuitToLean(&circuit, ecc.BN254)
+	if err != nil {
+		log.Fatal(err)
+	}
+	checkOutput(t, out)
+
+	assignment := GenericCallCircuit{In: [2]frontend.Variable{3, 5}, Out: 10}
+	err = test.IsSolved(&GenericCallCircuit{}, &assignment, ecc.BN254.ScalarField())
+	assert.NoError(t, err)
+}
+
+// Example: gadget with an Unwrap method, which isn't an adapter
+type Wrapped struct {
+	In frontend.Variable
+}
+
+func (gadget Wrapped) DefineGadget(api frontend.API) interface{} {
+	return api.Mul(gadget.In, 2)
+}
+
+func (gadget Wrapped) Unwrap() any {
+	return Spread{}
+}
+
+type WrappedCircuit struct {
+	In frontend.Variable
+}
+
+func (circuit *WrappedCircuit) Define(api frontend.API) error {
+	api.AssertIsEqual(abstractor.Call(api, Wrapped{circuit.In}), 4)
+	return nil
+}
+
+func TestWrappedCircuit(t *testing.T) {
+	circuit := WrappedCircuit{}
+	out, err := extractor.CircuitToLean(&circuit, ecc.BN254)
+	assert.NoError(t, err)
+	assert.Contains(t, out, "def Wrapped ")
+	assert.NotContains(t, out, "Spread")
+}
+
+// Example: gadget returning an array called with CallT
+type Swap struct {
+	In [2]frontend.Variable
+}
+
+func (gadget Swap) DefineGadget(api frontend.API) [2]frontend.Variable {
+	return [2]frontend.Variable{gadget.In[1], api.Add(gadget.In[0], 1)}
+}
+
+type ArrayCallCircuit struct {
+	In  [2]frontend.Variable
+	Out frontend.Variable
+}
+
+func (circuit *ArrayCallCircuit) AbsDefine(api abstractor.API) error {
+	swapped := abstractor.CallT[[2]frontend.Variable](api, Swap{circuit.In})
+	swapped = abstractor.CallT[[2]frontend.Variable](api, Swap{swapped})
+	api.AssertIsEqual(api.Add(swapped[0], swapped[1]), circuit.Out)
+	return nil
+}
+
+func (circuit *ArrayCallCircuit) Define(api frontend.API) error {
+	return abstractor.Concretize(api, circuit)
+}
+
+func TestArrayCallCircuit(t *testing.T) {
+	circuit := ArrayCallCircuit{}
+	out, err := extractor.CircuitToLean(&circuit, ecc.BN254)
+	if err != nil {
+		log.Fatal(err)
+	}
+	checkOutput(t, out)
+
+	assignment := ArrayCallCircuit{In: [2]frontend.Variable{3, 5}, Out: 10}
+	err = test.IsSolved(&ArrayCallCircuit{}, &assignment, ecc.BN254.ScalarField())
+	assert.NoError(t, err)
+}
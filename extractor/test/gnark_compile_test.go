@@ -0,0 +1,51 @@
+package extractor_test
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/frontend/cs/scs"
+	"github.com/reilabs/gnark-lean-extractor/v3/extractor"
+	"github.com/stretchr/testify/assert"
+)
+
+// compiledCircuits returns the example circuits which are both extracted
+// and compiled by gnark. Each call returns new instances, because the
+// compilers assign the fields of the circuits.
+func compiledCircuits() map[string]frontend.Circuit {
+	doubleSlice := make([][]frontend.Variable, 3)
+	for i := range doubleSlice {
+		doubleSlice[i] = make([]frontend.Variable, 3)
+	}
+	return map[string]frontend.Circuit{
+		"MyCircuit":      &MyCircuit{},
+		"AnotherCircuit": &AnotherCircuit{In: make([]frontend.Variable, 4), Matrix: [2][2]int{{0, 36}, {1, 44}}},
+		"BooleanCircuit": &BooleanCircuit{},
+		"CommitCircuit":  &CommitCircuit{In: make([]frontend.Variable, 3)},
+		"CrumbCircuit":   &CrumbCircuit{},
+		"DeferCircuit":   &DeferCircuit{In: make([]frontend.Variable, 2)},
+		"MerkleRecover":  &MerkleRecover{},
+		"RangeCheck":     &RangeCheckCircuit{},
+		"StructOutput":   &StructOutputCircuit{},
+		"ToBinary":       &ToBinaryCircuit{Double: doubleSlice},
+	}
+}
+
+// TestGnarkCompile checks that the circuits extracted to Lean are also
+// compiled by the version of gnark the extractor depends on, so that
+// both implementations of frontend.API accept the same code.
+func TestGnarkCompile(t *testing.T) {
+	builders := map[string]frontend.NewBuilder{"r1cs": r1cs.NewBuilder, "scs": scs.NewBuilder}
+	for name, circuit := range compiledCircuits() {
+		_, err := extractor.CircuitToLean(circuit, ecc.BN254)
+		assert.NoError(t, err, "extracting %s", name)
+	}
+	for builderName, builder := range builders {
+		for name, circuit := range compiledCircuits() {
+			_, err := frontend.Compile(ecc.BN254.ScalarField(), builder, circuit)
+			assert.NoError(t, err, "compiling %s with %s", name, builderName)
+		}
+	}
+}
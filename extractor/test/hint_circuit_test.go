@@ -0,0 +1,68 @@
+package extractor_test
+
+import (
+	"log"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/constraint/solver"
+	"github.com/consensys/gnark/frontend"
+	"github.com/reilabs/gnark-lean-extractor/v3/abstractor"
+	"github.com/reilabs/gnark-lean-extractor/v3/extractor"
+)
+
+func init() {
+	solver.RegisterHint(divModHint)
+}
+
+// divModHint returns the quotient and the remainder of inputs[0] / inputs[1]
+func divModHint(field *big.Int, inputs []*big.Int, outputs []*big.Int) error {
+	outputs[0].DivMod(inputs[0], inputs[1], outputs[1])
+	return nil
+}
+
+// Example: gadget with hint
+type DivMod struct {
+	Dividend frontend.Variable
+	Divisor  frontend.Variable
+}
+
+func (gadget DivMod) DefineGadget(api frontend.API) interface{} {
+	res, err := api.Compiler().NewHint(divModHint, 2, gadget.Dividend, gadget.Divisor)
+	if err != nil {
+		panic(err)
+	}
+	api.AssertIsEqual(gadget.Dividend, api.Add(api.Mul(res[0], gadget.Divisor), res[1]))
+	return res
+}
+
+type HintCircuit struct {
+	Dividend frontend.Variable
+	Divisor  frontend.Variable
+	Quotient frontend.Variable
+}
+
+func (circuit *HintCircuit) Define(api frontend.API) error {
+	res := abstractor.Call1(api, DivMod{circuit.Dividend, circuit.Divisor})
+	api.AssertIsEqual(res[0], circuit.Quotient)
+
+	hinter := api.Compiler().(interface {
+		NewHintForId(id solver.HintID, nbOutputs int, inputs ...frontend.Variable) ([]frontend.Variable, error)
+	})
+	unconstrained, err := hinter.NewHintForId(solver.GetHintID(divModHint), 2, circuit.Dividend, circuit.Divisor)
+	if err != nil {
+		return err
+	}
+	api.AssertIsEqual(unconstrained[0], circuit.Quotient)
+	return nil
+}
+
+func TestHintCircuit(t *testing.T) {
+	assignment := HintCircuit{}
+	out, err := extractor.CircuitToLean(&assignment, ecc.BN254)
+	if err != nil {
+		log.Fatal(err)
+	}
+	checkOutput(t, out)
+}
@@ -0,0 +1,64 @@
+package extractor_test
+
+import (
+	"log"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/reilabs/gnark-lean-extractor/v3/abstractor"
+	"github.com/reilabs/gnark-lean-extractor/v3/extractor"
+)
+
+// Example: gadgets exported in the code of their callers
+type SwapPair struct {
+	Pair [2]frontend.Variable
+}
+
+func (gadget SwapPair) DefineGadget(api frontend.API) []frontend.Variable {
+	return []frontend.Variable{gadget.Pair[1], api.Neg(gadget.Pair[0])}
+}
+
+// Inline makes SwapPair choose to be inlined
+func (gadget SwapPair) Inline() bool {
+	return true
+}
+
+type HashPair struct {
+	Pair [2]frontend.Variable
+}
+
+func (gadget HashPair) DefineGadget(api frontend.API) frontend.Variable {
+	swapped := abstractor.CallT[[]frontend.Variable](api, SwapPair{[2]frontend.Variable{gadget.Pair[0], gadget.Pair[1]}})
+	api.AssertIsDifferent(swapped[0], swapped[1])
+	return abstractor.Call(api, DummyHash{swapped[0], swapped[1]})
+}
+
+type InlineCircuit struct {
+	In  [2]frontend.Variable
+	Out frontend.Variable
+}
+
+func (circuit *InlineCircuit) Define(api frontend.API) error {
+	hash := abstractor.CallT[frontend.Variable](api, HashPair{circuit.In})
+	api.AssertIsEqual(abstractor.Call(api, DummyHash{hash, circuit.Out}), 0)
+	return nil
+}
+
+func TestInlineCircuit(t *testing.T) {
+	assignment := InlineCircuit{}
+	out, err := extractor.CircuitToLean(&assignment, ecc.BN254, extractor.WithInlineBelow(2))
+	if err != nil {
+		log.Fatal(err)
+	}
+	checkOutput(t, out)
+}
+
+func TestInlineByName(t *testing.T) {
+	assignment := InlineCircuit{}
+	out, err := extractor.CircuitToLean(&assignment, ecc.BN254, extractor.WithInline("HashPair"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	checkOutput(t, out)
+}
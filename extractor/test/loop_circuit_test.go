@@ -0,0 +1,80 @@
+package extractor_test
+
+import (
+	"log"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/reilabs/gnark-lean-extractor/v3/abstractor"
+	"github.com/reilabs/gnark-lean-extractor/v3/extractor"
+)
+
+// Example: loops carrying more than one value and using the
+// same argument in each iteration
+type ScaledSumProd struct {
+	Values [6]frontend.Variable
+	Scale  frontend.Variable
+}
+
+func (gadget ScaledSumProd) DefineGadget(api frontend.API) []frontend.Variable {
+	sum := frontend.Variable(0)
+	prod := frontend.Variable(1)
+	// The first and the last values are skipped
+	for i := 1; i < len(gadget.Values)-1; i++ {
+		sum = api.Add(sum, api.Mul(gadget.Values[i], gadget.Scale))
+		prod = api.Mul(prod, gadget.Values[i])
+	}
+	return []frontend.Variable{sum, prod}
+}
+
+type LoopCircuit struct {
+	Values [6]frontend.Variable
+	Scale  frontend.Variable
+	Sum    frontend.Variable
+	Prod   frontend.Variable
+	Bits   [4]frontend.Variable
+}
+
+func (circuit *LoopCircuit) Define(api frontend.API) error {
+	r := abstractor.CallT[[]frontend.Variable](api, ScaledSumProd{circuit.Values, circuit.Scale})
+	api.AssertIsEqual(r[0], circuit.Sum)
+	api.AssertIsEqual(r[1], circuit.Prod)
+	for i := 0; i < len(circuit.Bits); i++ {
+		api.AssertIsBoolean(circuit.Bits[i])
+	}
+	return nil
+}
+
+func TestLoopCircuit(t *testing.T) {
+	assignment := LoopCircuit{}
+	out, err := extractor.CircuitToLean(&assignment, ecc.BN254, extractor.WithLoopRecovery())
+	if err != nil {
+		log.Fatal(err)
+	}
+	checkOutput(t, out)
+}
+
+// Example: loop iterating over the same vector from different elements
+type ShiftedLoopCircuit struct {
+	Values [5]frontend.Variable
+	Sum    frontend.Variable
+}
+
+func (circuit *ShiftedLoopCircuit) Define(api frontend.API) error {
+	sum := frontend.Variable(0)
+	for i := 0; i < len(circuit.Values)-1; i++ {
+		sum = api.Add(sum, api.Mul(circuit.Values[i], circuit.Values[i+1]))
+	}
+	api.AssertIsEqual(sum, circuit.Sum)
+	return nil
+}
+
+func TestShiftedLoopCircuit(t *testing.T) {
+	assignment := ShiftedLoopCircuit{}
+	out, err := extractor.CircuitToLean(&assignment, ecc.BN254, extractor.WithLoopRecovery())
+	if err != nil {
+		log.Fatal(err)
+	}
+	checkOutput(t, out)
+}
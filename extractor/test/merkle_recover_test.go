@@ -48,3 +48,12 @@ func TestMerkleRecover(t *testing.T) {
 	}
 	checkOutput(t, out)
 }
+
+func TestMerkleRecoverLoop(t *testing.T) {
+	assignment := MerkleRecover{}
+	out, err := extractor.CircuitToLean(&assignment, ecc.BN254, extractor.WithLoopRecovery())
+	if err != nil {
+		log.Fatal(err)
+	}
+	checkOutput(t, out)
+}
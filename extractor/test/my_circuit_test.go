@@ -2,6 +2,7 @@ package extractor_test
 
 import (
 	"log"
+	"strings"
 	"testing"
 
 	"github.com/consensys/gnark-crypto/ecc"
@@ -30,3 +31,56 @@ func TestMyCircuit(t *testing.T) {
 	}
 	checkOutput(t, out)
 }
+
+func TestMyCircuitExtract(t *testing.T) {
+	circuit := MyCircuit{}
+	out := strings.Builder{}
+	diagnostics, err := extractor.Extract(&circuit,
+		extractor.WithOutput(&out),
+		extractor.WithNamespace("Readme"),
+		extractor.WithImports("Mathlib.Tactic"),
+		extractor.WithSetOptions("maxHeartbeats 400000"),
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(diagnostics) != 0 {
+		t.Fatalf("unexpected diagnostics %v", diagnostics)
+	}
+	checkOutput(t, out.String())
+}
+
+func TestMyCircuitTarget(t *testing.T) {
+	circuit := MyCircuit{}
+	target := extractor.Target{
+		Imports: []string{"ProvenZk.Gates", "ProvenZk.Ext.Vector", "MyProject.Prelude"},
+		Gates:   extractor.GatesGnark8,
+	}
+	out, err := extractor.CircuitToLean(&circuit, ecc.BN254, extractor.WithTarget(target))
+	if err != nil {
+		log.Fatal(err)
+	}
+	checkOutput(t, out)
+}
+
+func TestMyCircuitTargetCopied(t *testing.T) {
+	target := extractor.ProvenZK14Gnark8()
+	target.Imports[0] = "MyProject.Gates"
+	option := extractor.WithTarget(target)
+	target.Imports[0] = "Changed.After.Option"
+
+	out, err := extractor.CircuitToLean(&MyCircuit{}, ecc.BN254, option)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if !strings.HasPrefix(out, "import MyProject.Gates\n") || !strings.Contains(out, "abbrev Gates := GatesGnark8 Order") {
+		t.Fatalf("unexpected prelude\n%s", out)
+	}
+	out, err = extractor.CircuitToLean(&MyCircuit{}, ecc.BN254)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if !strings.HasPrefix(out, "import ProvenZk.Gates\n") {
+		t.Fatalf("the default target changed\n%s", out)
+	}
+}
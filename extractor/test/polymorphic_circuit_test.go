@@ -0,0 +1,122 @@
+package extractor_test
+
+import (
+	"log"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/reilabs/gnark-lean-extractor/v3/abstractor"
+	"github.com/reilabs/gnark-lean-extractor/v3/extractor"
+)
+
+// Example: gadgets used with vectors of different sizes
+type BitsCheck struct {
+	Bits  []frontend.Variable
+	Value frontend.Variable
+}
+
+func (gadget BitsCheck) DefineGadget(api frontend.API) interface{} {
+	api.AssertIsEqual(api.FromBinary(gadget.Bits...), gadget.Value)
+	return nil
+}
+
+type DoubleBitsCheck struct {
+	Bits  []frontend.Variable
+	Value frontend.Variable
+}
+
+func (gadget DoubleBitsCheck) DefineGadget(api frontend.API) []frontend.Variable {
+	abstractor.CallVoid(api, BitsCheck{gadget.Bits, gadget.Value})
+	abstractor.CallVoid(api, BitsCheck{gadget.Bits, api.Add(gadget.Value, 0)})
+	return gadget.Bits
+}
+
+type FirstBit struct {
+	Bits []frontend.Variable
+}
+
+func (gadget FirstBit) DefineGadget(api frontend.API) frontend.Variable {
+	return gadget.Bits[0]
+}
+
+type PolymorphicCircuit struct {
+	Short [8]frontend.Variable
+	Long  [16]frontend.Variable
+	A     frontend.Variable
+	B     frontend.Variable
+}
+
+func (circuit *PolymorphicCircuit) AbsDefine(api abstractor.API) error {
+	short := abstractor.CallT[[]frontend.Variable](api, DoubleBitsCheck{circuit.Short[:], circuit.A})
+	long := abstractor.CallT[[]frontend.Variable](api, DoubleBitsCheck{circuit.Long[:], circuit.B})
+	api.AssertIsEqual(abstractor.CallT[frontend.Variable](api, FirstBit{short}), 0)
+	api.AssertIsEqual(abstractor.CallT[frontend.Variable](api, FirstBit{long}), 0)
+	return nil
+}
+
+func (circuit *PolymorphicCircuit) Define(api frontend.API) error {
+	return abstractor.Concretize(api, circuit)
+}
+
+func TestPolymorphicCircuit(t *testing.T) {
+	circuit := PolymorphicCircuit{}
+	out, err := extractor.CircuitToLean(&circuit, ecc.BN254, extractor.WithPolymorphicGadgets())
+	if err != nil {
+		log.Fatal(err)
+	}
+	checkOutput(t, out)
+}
+
+// Example: gadget whose instances only partially differ by sizes
+type ScaledBits struct {
+	Bits   []frontend.Variable
+	Factor int
+}
+
+func (gadget ScaledBits) DefineGadget(api frontend.API) frontend.Variable {
+	return api.Mul(api.FromBinary(gadget.Bits...), gadget.Factor)
+}
+
+// Example: gadget whose body depends on an integer field, but not
+// only through its value
+type SquaredBits struct {
+	Bits   []frontend.Variable
+	Factor int
+}
+
+func (gadget SquaredBits) DefineGadget(api frontend.API) frontend.Variable {
+	return api.Mul(api.FromBinary(gadget.Bits...), gadget.Factor*gadget.Factor)
+}
+
+type PartialPolymorphicCircuit struct {
+	Short [4]frontend.Variable
+	Long  [8]frontend.Variable
+}
+
+func (circuit *PartialPolymorphicCircuit) AbsDefine(api abstractor.API) error {
+	sum := frontend.Variable(0)
+	for _, factor := range []int{2, 3} {
+		sum = api.Add(sum, abstractor.CallT[frontend.Variable](api, ScaledBits{circuit.Short[:], factor}))
+		sum = api.Add(sum, abstractor.CallT[frontend.Variable](api, ScaledBits{circuit.Long[:], factor}))
+		sum = api.Add(sum, abstractor.CallT[frontend.Variable](api, SquaredBits{circuit.Short[:], factor}))
+		sum = api.Add(sum, abstractor.CallT[frontend.Variable](api, SquaredBits{circuit.Long[:], factor}))
+	}
+	sum = api.Add(sum, abstractor.CallT[frontend.Variable](api, ScaledBits{circuit.Short[:], 5}))
+	sum = api.Add(sum, abstractor.CallT[frontend.Variable](api, SquaredBits{circuit.Short[:], 5}))
+	api.AssertIsEqual(sum, 0)
+	return nil
+}
+
+func (circuit *PartialPolymorphicCircuit) Define(api frontend.API) error {
+	return abstractor.Concretize(api, circuit)
+}
+
+func TestPartialPolymorphicCircuit(t *testing.T) {
+	circuit := PartialPolymorphicCircuit{}
+	out, err := extractor.CircuitToLean(&circuit, ecc.BN254, extractor.WithPolymorphicGadgets())
+	if err != nil {
+		log.Fatal(err)
+	}
+	checkOutput(t, out)
+}
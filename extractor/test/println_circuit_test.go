@@ -0,0 +1,52 @@
+package extractor_test
+
+import (
+	"log"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/reilabs/gnark-lean-extractor/v3/abstractor"
+	"github.com/reilabs/gnark-lean-extractor/v3/extractor"
+)
+
+// Example: circuit with debugging statements
+type PrintlnGadget struct {
+	In []frontend.Variable
+}
+
+func (gadget PrintlnGadget) DefineGadget(api frontend.API) interface{} {
+	sum := api.Add(gadget.In[0], gadget.In[1], gadget.In[2])
+	api.Println("sum of", gadget.In, "is", sum)
+	return sum
+}
+
+type PrintlnCircuit struct {
+	In  []frontend.Variable
+	Out frontend.Variable
+}
+
+func (circuit *PrintlnCircuit) Define(api frontend.API) error {
+	sum := abstractor.Call(api, PrintlnGadget{circuit.In})
+	api.Println("expected\nresult:", circuit.Out, 42)
+	api.AssertIsEqual(sum, circuit.Out)
+	return nil
+}
+
+func TestPrintlnCircuit(t *testing.T) {
+	assignment := PrintlnCircuit{In: make([]frontend.Variable, 3)}
+	out, err := extractor.CircuitToLean(&assignment, ecc.BN254, extractor.WithPrintlnComments())
+	if err != nil {
+		log.Fatal(err)
+	}
+	checkOutput(t, out)
+}
+
+func TestPrintlnCircuitDropped(t *testing.T) {
+	assignment := PrintlnCircuit{In: make([]frontend.Variable, 3)}
+	out, err := extractor.CircuitToLean(&assignment, ecc.BN254)
+	if err != nil {
+		log.Fatal(err)
+	}
+	checkOutput(t, out)
+}
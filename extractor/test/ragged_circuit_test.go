@@ -0,0 +1,100 @@
+package extractor_test
+
+import (
+	"log"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/reilabs/gnark-lean-extractor/v3/abstractor"
+	"github.com/reilabs/gnark-lean-extractor/v3/extractor"
+)
+
+// Example: gadget taking a ragged slice
+type RaggedSum struct {
+	Paths [][]frontend.Variable
+}
+
+func (gadget RaggedSum) DefineGadget(api frontend.API) []frontend.Variable {
+	sums := make([]frontend.Variable, len(gadget.Paths))
+	for i, path := range gadget.Paths {
+		sums[i] = api.Add(0, 0, path...)
+	}
+	return sums
+}
+
+type RaggedCircuit struct {
+	Paths [][]frontend.Variable
+	Roots []frontend.Variable
+}
+
+func (circuit *RaggedCircuit) AbsDefine(api abstractor.API) error {
+	sums := abstractor.CallT[[]frontend.Variable](api, RaggedSum{circuit.Paths})
+	for i, sum := range sums {
+		api.AssertIsEqual(sum, circuit.Roots[i])
+	}
+	api.AssertIsEqual(circuit.Paths[1][1], circuit.Paths[0][2])
+	return nil
+}
+
+func (circuit *RaggedCircuit) Define(api frontend.API) error {
+	return abstractor.Concretize(api, circuit)
+}
+
+func TestRaggedCircuit(t *testing.T) {
+	circuit := RaggedCircuit{
+		Paths: [][]frontend.Variable{make([]frontend.Variable, 3), make([]frontend.Variable, 2)},
+		Roots: make([]frontend.Variable, 2),
+	}
+	out, err := extractor.CircuitToLean(&circuit, ecc.BN254)
+	if err != nil {
+		log.Fatal(err)
+	}
+	checkOutput(t, out)
+}
+
+// Example: batch of proofs with different depths
+type BatchProofSum struct {
+	Proofs []MerkleProof
+}
+
+func (gadget BatchProofSum) DefineGadget(api frontend.API) []frontend.Variable {
+	sums := make([]frontend.Variable, len(gadget.Proofs))
+	for i, proof := range gadget.Proofs {
+		sums[i] = abstractor.CallT[frontend.Variable](api, ProofSum{proof})
+	}
+	return sums
+}
+
+type RaggedStructCircuit struct {
+	Proofs []MerkleProof
+	Roots  []frontend.Variable
+}
+
+func (circuit *RaggedStructCircuit) AbsDefine(api abstractor.API) error {
+	sums := abstractor.CallT[[]frontend.Variable](api, BatchProofSum{circuit.Proofs})
+	for i, sum := range sums {
+		api.AssertIsEqual(sum, circuit.Roots[i])
+	}
+	api.AssertIsEqual(circuit.Proofs[1].Path[2], circuit.Proofs[0].Path[0])
+	return nil
+}
+
+func (circuit *RaggedStructCircuit) Define(api frontend.API) error {
+	return abstractor.Concretize(api, circuit)
+}
+
+func TestRaggedStructCircuit(t *testing.T) {
+	circuit := RaggedStructCircuit{
+		Proofs: []MerkleProof{
+			{Path: make([]frontend.Variable, 1)},
+			{Path: make([]frontend.Variable, 3)},
+		},
+		Roots: make([]frontend.Variable, 2),
+	}
+	out, err := extractor.CircuitToLean(&circuit, ecc.BN254)
+	if err != nil {
+		log.Fatal(err)
+	}
+	checkOutput(t, out)
+}
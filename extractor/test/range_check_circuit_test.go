@@ -0,0 +1,43 @@
+package extractor_test
+
+import (
+	"log"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/rangecheck"
+	"github.com/reilabs/gnark-lean-extractor/v3/abstractor"
+	"github.com/reilabs/gnark-lean-extractor/v3/extractor"
+)
+
+// Example: range checks using gnark std library
+type RangeCheckGadget struct {
+	In   frontend.Variable
+	Bits int
+}
+
+func (gadget RangeCheckGadget) DefineGadget(api frontend.API) interface{} {
+	rangecheck.New(api).Check(gadget.In, gadget.Bits)
+	return nil
+}
+
+type RangeCheckCircuit struct {
+	Amount  frontend.Variable
+	Balance frontend.Variable
+}
+
+func (circuit *RangeCheckCircuit) Define(api frontend.API) error {
+	abstractor.CallVoid(api, RangeCheckGadget{circuit.Amount, 64})
+	rangecheck.New(api).Check(api.Sub(circuit.Balance, circuit.Amount), 64)
+	return nil
+}
+
+func TestRangeCheckCircuit(t *testing.T) {
+	assignment := RangeCheckCircuit{}
+	out, err := extractor.CircuitToLean(&assignment, ecc.BN254)
+	if err != nil {
+		log.Fatal(err)
+	}
+	checkOutput(t, out)
+}
@@ -0,0 +1,99 @@
+package extractor_test
+
+import (
+	"log"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/reilabs/gnark-lean-extractor/v3/abstractor"
+	"github.com/reilabs/gnark-lean-extractor/v3/extractor"
+	"github.com/stretchr/testify/assert"
+)
+
+type MerkleProof struct {
+	Leaf frontend.Variable
+	Path []frontend.Variable
+}
+
+type Claim struct {
+	Owner Point
+	Proof MerkleProof
+}
+
+// Example: gadgets taking structs as arguments
+type ProofSum struct {
+	Proof MerkleProof
+}
+
+func (gadget ProofSum) DefineGadget(api frontend.API) frontend.Variable {
+	sum := gadget.Proof.Leaf
+	for _, p := range gadget.Proof.Path {
+		sum = api.Add(sum, p)
+	}
+	return sum
+}
+
+type ClaimCheck struct {
+	Claims []Claim
+	Owner  Point
+}
+
+func (gadget ClaimCheck) DefineGadget(api frontend.API) []frontend.Variable {
+	sums := make([]frontend.Variable, len(gadget.Claims))
+	for i, claim := range gadget.Claims {
+		api.AssertIsEqual(claim.Owner.X, gadget.Owner.X)
+		sums[i] = abstractor.CallT[frontend.Variable](api, ProofSum{claim.Proof})
+	}
+	return sums
+}
+
+type StructInputCircuit struct {
+	Owner  Point
+	Claims [2]Claim
+	Sums   [2]frontend.Variable
+}
+
+func (circuit *StructInputCircuit) AbsDefine(api abstractor.API) error {
+	sums := abstractor.CallT[[]frontend.Variable](api, ClaimCheck{circuit.Claims[:], circuit.Owner})
+	for i := range sums {
+		api.AssertIsEqual(sums[i], circuit.Sums[i])
+	}
+	first := abstractor.CallT[frontend.Variable](api, ProofSum{MerkleProof{circuit.Owner.Y, circuit.Claims[0].Proof.Path}})
+	api.AssertIsEqual(first, circuit.Sums[0])
+	return nil
+}
+
+func (circuit *StructInputCircuit) Define(api frontend.API) error {
+	return abstractor.Concretize(api, circuit)
+}
+
+func newStructInputCircuit() StructInputCircuit {
+	circuit := StructInputCircuit{}
+	for i := range circuit.Claims {
+		circuit.Claims[i].Proof.Path = make([]frontend.Variable, 3)
+	}
+	return circuit
+}
+
+func TestStructInputCircuit(t *testing.T) {
+	circuit := newStructInputCircuit()
+	out, err := extractor.CircuitToLean(&circuit, ecc.BN254)
+	if err != nil {
+		log.Fatal(err)
+	}
+	checkOutput(t, out)
+
+	assignment := StructInputCircuit{
+		Owner: Point{1, 2},
+		Claims: [2]Claim{
+			{Point{1, 5}, MerkleProof{2, []frontend.Variable{1, 2, 3}}},
+			{Point{1, 7}, MerkleProof{4, []frontend.Variable{0, 0, 1}}},
+		},
+		Sums: [2]frontend.Variable{8, 5},
+	}
+	empty := newStructInputCircuit()
+	err = test.IsSolved(&empty, &assignment, ecc.BN254.ScalarField())
+	assert.NoError(t, err)
+}
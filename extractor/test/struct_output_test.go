@@ -0,0 +1,141 @@
+package extractor_test
+
+import (
+	"log"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/reilabs/gnark-lean-extractor/v3/abstractor"
+	"github.com/reilabs/gnark-lean-extractor/v3/extractor"
+	"github.com/stretchr/testify/assert"
+)
+
+type Point struct {
+	X frontend.Variable
+	Y frontend.Variable
+}
+
+type Segment struct {
+	Ends   []Point
+	Length frontend.Variable
+	Steps  []frontend.Variable
+}
+
+// Example: gadgets returning structs
+type Double struct {
+	X frontend.Variable
+	Y frontend.Variable
+}
+
+func (gadget Double) DefineGadget(api frontend.API) Point {
+	return Point{api.Add(gadget.X, gadget.X), api.Add(gadget.Y, gadget.Y)}
+}
+
+type Walk struct {
+	X     frontend.Variable
+	Y     frontend.Variable
+	Steps [3]frontend.Variable
+}
+
+func (gadget Walk) DefineGadget(api frontend.API) Segment {
+	end := Point{gadget.X, gadget.Y}
+	for _, step := range gadget.Steps {
+		end = abstractor.CallT[Point](api, Double{api.Add(end.X, step), end.Y})
+	}
+	return Segment{
+		Ends:   []Point{{gadget.X, gadget.Y}, end},
+		Length: api.Sub(end.X, gadget.X),
+		Steps:  gadget.Steps[:],
+	}
+}
+
+// Example: gadget returning a struct with an array and fields
+// which don't contain variables
+type Scaled struct {
+	Coords [2]frontend.Variable
+	Factor int
+	Label  string
+}
+
+type Scale struct {
+	X      frontend.Variable
+	Y      frontend.Variable
+	Factor int
+}
+
+func (gadget Scale) DefineGadget(api frontend.API) Scaled {
+	return Scaled{
+		Coords: [2]frontend.Variable{api.Mul(gadget.X, gadget.Factor), api.Mul(gadget.Y, gadget.Factor)},
+		Factor: gadget.Factor,
+		Label:  "scaled",
+	}
+}
+
+type ScaledOutputCircuit struct {
+	X   frontend.Variable
+	Y   frontend.Variable
+	Out frontend.Variable
+}
+
+func (circuit *ScaledOutputCircuit) AbsDefine(api abstractor.API) error {
+	scaled := abstractor.CallT[Scaled](api, Scale{circuit.X, circuit.Y, 3})
+	if scaled.Factor != 3 || scaled.Label != "scaled" {
+		panic("fields without variables must be kept")
+	}
+	api.AssertIsEqual(api.Add(scaled.Coords[0], scaled.Coords[1]), circuit.Out)
+	return nil
+}
+
+func (circuit *ScaledOutputCircuit) Define(api frontend.API) error {
+	return abstractor.Concretize(api, circuit)
+}
+
+type StructOutputCircuit struct {
+	X     frontend.Variable
+	Y     frontend.Variable
+	Steps [3]frontend.Variable
+	Out   frontend.Variable
+}
+
+func (circuit *StructOutputCircuit) AbsDefine(api abstractor.API) error {
+	segment := abstractor.CallT[Segment](api, Walk{circuit.X, circuit.Y, circuit.Steps})
+	api.AssertIsEqual(segment.Ends[1].Y, circuit.Out)
+	api.AssertIsEqual(segment.Length, segment.Steps[0])
+	return nil
+}
+
+func (circuit *StructOutputCircuit) Define(api frontend.API) error {
+	return abstractor.Concretize(api, circuit)
+}
+
+func TestStructOutputCircuit(t *testing.T) {
+	circuit := StructOutputCircuit{}
+	out, err := extractor.CircuitToLean(&circuit, ecc.BN254)
+	if err != nil {
+		log.Fatal(err)
+	}
+	checkOutput(t, out)
+
+	// With zero steps, X and Y are doubled three times
+	assignment := StructOutputCircuit{X: 1, Y: 1, Steps: [3]frontend.Variable{0, 0, 0}, Out: 8}
+	err = test.IsSolved(&StructOutputCircuit{}, &assignment, ecc.BN254.ScalarField())
+	assert.Error(t, err)
+	assignment = StructOutputCircuit{X: 0, Y: 1, Steps: [3]frontend.Variable{0, 0, 0}, Out: 8}
+	err = test.IsSolved(&StructOutputCircuit{}, &assignment, ecc.BN254.ScalarField())
+	assert.NoError(t, err)
+}
+
+func TestScaledOutputCircuit(t *testing.T) {
+	circuit := ScaledOutputCircuit{}
+	out, err := extractor.CircuitToLean(&circuit, ecc.BN254)
+	if err != nil {
+		log.Fatal(err)
+	}
+	checkOutput(t, out)
+
+	assignment := ScaledOutputCircuit{X: 1, Y: 2, Out: 9}
+	err = test.IsSolved(&ScaledOutputCircuit{}, &assignment, ecc.BN254.ScalarField())
+	assert.NoError(t, err)
+}
@@ -2,6 +2,7 @@ package extractor_test
 
 import (
 	"log"
+	"strings"
 	"testing"
 
 	"github.com/consensys/gnark-crypto/ecc"
@@ -85,3 +86,20 @@ func TestToBinaryCircuit(t *testing.T) {
 	}
 	checkOutput(t, out)
 }
+
+func TestToBinaryCircuitRemovedLoop(t *testing.T) {
+	doubleSlice := make([][]frontend.Variable, 3)
+	for i := range doubleSlice {
+		doubleSlice[i] = make([]frontend.Variable, 3)
+	}
+	assignment := ToBinaryCircuit{Double: doubleSlice}
+	out, err := extractor.CircuitToLean(&assignment, ecc.BN254, extractor.WithDeadGateElimination(), extractor.WithLoopRecovery())
+	if err != nil {
+		log.Fatal(err)
+	}
+	// The gates removed by DCE aren't recovered as loops
+	if strings.Contains(out, "_loop_") {
+		t.Fatalf("unexpected loop in\n%s", out)
+	}
+	checkOutput(t, out)
+}
@@ -2,6 +2,7 @@ package extractor_test
 
 import (
 	"log"
+	"strings"
 	"testing"
 
 	"github.com/consensys/gnark-crypto/ecc"
@@ -60,6 +61,15 @@ func TestTwoGadgets(t *testing.T) {
 	checkOutput(t, out)
 }
 
+func TestTwoGadgetsDeadGates(t *testing.T) {
+	assignment := TwoGadgets{Num: 11}
+	out, err := extractor.CircuitToLean(&assignment, ecc.BN254, extractor.WithDeadGateElimination())
+	if err != nil {
+		log.Fatal(err)
+	}
+	checkOutput(t, out)
+}
+
 func TestExtractGadgets(t *testing.T) {
 	assignment_1 := DummyHash{}
 	assignment_2 := MySecondWidget{Num: 11}
@@ -115,3 +125,18 @@ func TestExtractCircuits(t *testing.T) {
 	}
 	checkOutput(t, out)
 }
+
+func TestExtractCircuitsOptions(t *testing.T) {
+	circuits := []frontend.Circuit{&MerkleRecover{}, &LoopCircuit{}}
+	out := strings.Builder{}
+	_, err := extractor.ExtractList(circuits,
+		extractor.WithNamespace("MultipleCircuitsLoops"),
+		extractor.WithLoopRecovery(),
+		extractor.WithImports("Mathlib.Tactic"),
+		extractor.WithOutput(&out),
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+	checkOutput(t, out.String())
+}
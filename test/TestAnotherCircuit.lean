@@ -12,7 +12,6 @@ abbrev Gates := GatesGnark9 Order
 
 def IntArrayGadget_4 (In: Vector F 4) (k: Vector F 3 -> Prop): Prop :=
     ∃gate_0, Gates.from_binary In gate_0 ∧
-    ∃_ignored_, _ignored_ = Gates.mul (0:F) (36:F) ∧
     k vec![gate_0, gate_0, gate_0]
 
 def circuit (In: Vector F 4): Prop :=
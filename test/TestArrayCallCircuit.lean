@@ -0,0 +1,24 @@
+import ProvenZk.Gates
+import ProvenZk.Ext.Vector
+
+set_option linter.unusedVariables false
+
+namespace ArrayCallCircuit
+
+def Order : ℕ := 0x30644e72e131a029b85045b68181585d2833e84879b9709143e1f593f0000001
+variable [Fact (Nat.Prime Order)]
+abbrev F := ZMod Order
+abbrev Gates := GatesGnark9 Order
+
+def Swap_2 (In: Vector F 2) (k: Vector F 2 -> Prop): Prop :=
+    ∃gate_0, gate_0 = Gates.add In[0] (1:F) ∧
+    k vec![In[1], gate_0]
+
+def circuit (In: Vector F 2) (Out: F): Prop :=
+    Swap_2 In fun gate_0 =>
+    Swap_2 gate_0 fun gate_1 =>
+    ∃gate_2, gate_2 = Gates.add gate_1[0] gate_1[1] ∧
+    Gates.eq gate_2 Out ∧
+    True
+
+end ArrayCallCircuit
\ No newline at end of file
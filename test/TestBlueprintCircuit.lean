@@ -0,0 +1,27 @@
+import ProvenZk.Gates
+import ProvenZk.Ext.Vector
+
+set_option linter.unusedVariables false
+
+namespace BlueprintCircuit
+
+def Order : ℕ := 0x30644e72e131a029b85045b68181585d2833e84879b9709143e1f593f0000001
+variable [Fact (Nat.Prime Order)]
+abbrev F := ZMod Order
+abbrev Gates := GatesGnark9 Order
+
+
+
+def circuit (Entries: Vector F 3) (Index: F) (Out: F): Prop :=
+    ∃gate_0: Vector F 1, -- hint constraint.BlueprintLookupHint
+    ∃gate_1, gate_1 = Gates.mul gate_0[0] Out ∧
+    Gates.eq gate_1 Entries[0] ∧
+    ∃gate_3: Vector F 1, -- hint wire
+    ∃gate_4, gate_4 = Gates.mul (-1:F) gate_3[0] ∧
+    ∃gate_5, gate_5 = Gates.mul Entries[1] Entries[2] ∧
+    ∃gate_6, gate_6 = Gates.add gate_4 gate_5 ∧
+    Gates.eq gate_6 (0:F) ∧
+    Gates.eq gate_3[0] Out ∧
+    True
+
+end BlueprintCircuit
\ No newline at end of file
@@ -0,0 +1,29 @@
+import ProvenZk.Gates
+import ProvenZk.Ext.Vector
+
+set_option linter.unusedVariables false
+
+namespace BooleanCircuit
+
+def Order : ℕ := 0x30644e72e131a029b85045b68181585d2833e84879b9709143e1f593f0000001
+variable [Fact (Nat.Prime Order)]
+abbrev F := ZMod Order
+abbrev Gates := GatesGnark9 Order
+
+def BoolSelect (Cond: F) (A: F) (B: F) (k: F -> Prop): Prop :=
+    Gates.is_bool Cond ∧
+    ∃gate_1, gate_1 = Gates.sub A B ∧
+    ∃gate_2, gate_2 = Gates.mul Cond gate_1 ∧
+    ∃gate_3, gate_3 = Gates.add gate_2 B ∧
+    k gate_3
+
+def circuit (In: F) (Flag: F) (Out: F): Prop :=
+    ∃gate_0, Gates.to_binary In 2 gate_0 ∧
+    ∃gate_1, Gates.is_zero In gate_1 ∧
+    ∃gate_2, Gates.xor gate_0[0] gate_0[1] gate_2 ∧
+    Gates.is_bool Flag ∧
+    BoolSelect Flag gate_1 gate_2 fun gate_4 =>
+    Gates.eq gate_4 Out ∧
+    True
+
+end BooleanCircuit
\ No newline at end of file
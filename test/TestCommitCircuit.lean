@@ -0,0 +1,30 @@
+import ProvenZk.Gates
+import ProvenZk.Ext.Vector
+
+set_option linter.unusedVariables false
+
+namespace CommitCircuit
+
+def Order : ℕ := 0x30644e72e131a029b85045b68181585d2833e84879b9709143e1f593f0000001
+variable [Fact (Nat.Prime Order)]
+abbrev F := ZMod Order
+abbrev Gates := GatesGnark9 Order
+
+namespace GatesExt
+axiom commit {n : ℕ} : Vector F n -> F
+end GatesExt
+
+
+
+def circuit (In: Vector F 3) (Expected: F): Prop :=
+    ∃gate_0, gate_0 = GatesExt.commit In ∧
+    ∃gate_1, gate_1 = Gates.mul In[2] gate_0 ∧
+    ∃gate_2, gate_2 = Gates.add gate_1 In[1] ∧
+    ∃gate_3, gate_3 = Gates.mul gate_2 gate_0 ∧
+    ∃gate_4, gate_4 = Gates.add gate_3 In[0] ∧
+    Gates.eq gate_4 Expected ∧
+    ∃gate_6, gate_6 = GatesExt.commit vec![gate_0, In[0], Expected] ∧
+    Gates.ne gate_6 (0:F) ∧
+    True
+
+end CommitCircuit
\ No newline at end of file
@@ -0,0 +1,23 @@
+import ProvenZk.Gates
+import ProvenZk.Ext.Vector
+
+set_option linter.unusedVariables false
+
+namespace ConcreteCircuit
+
+def Order : ℕ := 0x30644e72e131a029b85045b68181585d2833e84879b9709143e1f593f0000001
+variable [Fact (Nat.Prime Order)]
+abbrev F := ZMod Order
+abbrev Gates := GatesGnark9 Order
+
+def DummyHash (In_1: F) (In_2: F) (k: F -> Prop): Prop :=
+    ∃gate_0, gate_0 = Gates.mul In_1 In_2 ∧
+    k gate_0
+
+def circuit (In_1: F) (In_2: F) (Out: F): Prop :=
+    DummyHash In_1 In_2 fun gate_0 =>
+    ∃gate_1, gate_1 = Gates.add gate_0 In_1 ∧
+    Gates.eq gate_1 Out ∧
+    True
+
+end ConcreteCircuit
\ No newline at end of file
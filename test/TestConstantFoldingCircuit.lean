@@ -0,0 +1,27 @@
+import ProvenZk.Gates
+import ProvenZk.Ext.Vector
+
+set_option linter.unusedVariables false
+
+namespace ConstantFoldingCircuit
+
+def Order : ℕ := 0x30644e72e131a029b85045b68181585d2833e84879b9709143e1f593f0000001
+variable [Fact (Nat.Prime Order)]
+abbrev F := ZMod Order
+abbrev Gates := GatesGnark9 Order
+
+
+
+def circuit (In: F) (Out: F): Prop :=
+    ∃gate_0, gate_0 = Gates.mul In (42:F) ∧
+    ∃gate_1, gate_1 = Gates.add gate_0 (10944121435919637611123202872628637544274182200208017171849102093287904247808:F) ∧
+    Gates.eq gate_1 Out ∧
+    ∃gate_3, gate_3 = Gates.add In (3:F) ∧
+    ∃gate_3, gate_3 = Gates.add gate_3 Out ∧
+    ∃gate_4, gate_4 = Gates.mul (6:F) In ∧
+    ∃gate_4, gate_4 = Gates.mul gate_4 Out ∧
+    Gates.eq gate_3 gate_4 ∧
+    Gates.eq In Out ∧
+    True
+
+end ConstantFoldingCircuit
\ No newline at end of file
@@ -0,0 +1,28 @@
+import ProvenZk.Gates
+import ProvenZk.Ext.Vector
+
+set_option linter.unusedVariables false
+
+namespace CrumbCircuit
+
+def Order : ℕ := 0x30644e72e131a029b85045b68181585d2833e84879b9709143e1f593f0000001
+variable [Fact (Nat.Prime Order)]
+abbrev F := ZMod Order
+abbrev Gates := GatesGnark9 Order
+
+namespace GatesExt
+def is_crumb (a : F) : Prop := a.val < 4
+end GatesExt
+
+
+
+def circuit (In: F) (Crumbs: Vector F 3): Prop :=
+    GatesExt.is_crumb Crumbs[2] ∧
+    GatesExt.is_crumb Crumbs[1] ∧
+    ∃gate_2, gate_2 = Gates.mul_acc Crumbs[1] Crumbs[2] (4:F) ∧
+    GatesExt.is_crumb Crumbs[0] ∧
+    ∃gate_4, gate_4 = Gates.mul_acc Crumbs[0] gate_2 (4:F) ∧
+    Gates.eq gate_4 In ∧
+    True
+
+end CrumbCircuit
\ No newline at end of file
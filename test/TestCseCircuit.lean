@@ -0,0 +1,40 @@
+import ProvenZk.Gates
+import ProvenZk.Ext.Vector
+
+set_option linter.unusedVariables false
+
+namespace CseCircuit
+
+def Order : ℕ := 0x30644e72e131a029b85045b68181585d2833e84879b9709143e1f593f0000001
+variable [Fact (Nat.Prime Order)]
+abbrev F := ZMod Order
+abbrev Gates := GatesGnark9 Order
+
+def SquareSum (A: F) (B: F) (k: F -> Prop): Prop :=
+    ∃gate_0, gate_0 = Gates.mul A A ∧
+    ∃gate_1, gate_1 = Gates.mul B B ∧
+    ∃gate_2, gate_2 = Gates.add gate_0 gate_1 ∧
+    ∃gate_2, gate_2 = Gates.add gate_2 gate_0 ∧
+    k gate_2
+
+def DivMod (Dividend: F) (Divisor: F) (k: Vector F 2 -> Prop): Prop :=
+    ∃gate_0: Vector F 2, -- hint test_test.divModHint
+    ∃gate_1, gate_1 = Gates.mul gate_0[0] Divisor ∧
+    ∃gate_2, gate_2 = Gates.add gate_1 gate_0[1] ∧
+    Gates.eq Dividend gate_2 ∧
+    k gate_0
+
+def circuit (Path: Vector F 2) (Dividend: F) (Divisor: F): Prop :=
+    ∃gate_0, gate_0 = Gates.mul Path[0] Path[0] ∧
+    ∃gate_1, gate_1 = Gates.add gate_0 Path[1] ∧
+    Gates.eq gate_1 gate_1 ∧
+    SquareSum Path[0] Path[1] fun gate_3 =>
+    Gates.eq gate_3 gate_3 ∧
+    SquareSum Path[1] Path[0] fun gate_5 =>
+    Gates.eq gate_3 gate_5 ∧
+    DivMod Dividend Divisor fun gate_7 =>
+    DivMod Dividend Divisor fun gate_8 =>
+    Gates.eq gate_7[0] gate_8[0] ∧
+    True
+
+end CseCircuit
\ No newline at end of file
@@ -0,0 +1,29 @@
+import ProvenZk.Gates
+import ProvenZk.Ext.Vector
+
+set_option linter.unusedVariables false
+
+namespace DedupCircuit
+
+def Order : ℕ := 0x30644e72e131a029b85045b68181585d2833e84879b9709143e1f593f0000001
+variable [Fact (Nat.Prime Order)]
+abbrev F := ZMod Order
+abbrev Gates := GatesGnark9 Order
+
+def Rounded_1 (In: F) (k: F -> Prop): Prop :=
+    ∃gate_0, gate_0 = Gates.mul In (2:F) ∧
+    k gate_0
+
+def Rounded_3 (In: F) (k: F -> Prop): Prop :=
+    ∃gate_0, gate_0 = Gates.mul In (3:F) ∧
+    k gate_0
+
+def circuit (In: F): Prop :=
+    Rounded_1 In fun gate_0 =>
+    Rounded_1 In fun gate_1 =>
+    Rounded_3 In fun gate_2 =>
+    ∃gate_3, gate_3 = Gates.add gate_0 gate_1 ∧
+    Gates.eq gate_3 gate_2 ∧
+    True
+
+end DedupCircuit
\ No newline at end of file
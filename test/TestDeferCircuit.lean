@@ -0,0 +1,32 @@
+import ProvenZk.Gates
+import ProvenZk.Ext.Vector
+
+set_option linter.unusedVariables false
+
+namespace DeferCircuit
+
+def Order : ℕ := 0x30644e72e131a029b85045b68181585d2833e84879b9709143e1f593f0000001
+variable [Fact (Nat.Prime Order)]
+abbrev F := ZMod Order
+abbrev Gates := GatesGnark9 Order
+
+namespace GatesExt
+axiom commit {n : ℕ} : Vector F n -> F
+end GatesExt
+
+def ChallengeGadget_2 (In: Vector F 2) (k: F -> Prop): Prop :=
+    ∃gate_0, gate_0 = Gates.add In[0] In[1] ∧
+    ∃gate_1, gate_1 = GatesExt.commit In ∧
+    ∃gate_2, gate_2 = Gates.sub gate_1 In[0] ∧
+    Gates.ne gate_2 In[1] ∧
+    k gate_0
+
+def circuit (In: Vector F 2) (Out: F): Prop :=
+    ChallengeGadget_2 In fun gate_0 =>
+    Gates.eq gate_0 Out ∧
+    ∃gate_2, gate_2 = GatesExt.commit vec![In[0], Out] ∧
+    Gates.ne gate_2 Out ∧
+    Gates.eq In[1] Out ∧
+    True
+
+end DeferCircuit
\ No newline at end of file
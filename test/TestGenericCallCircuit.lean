@@ -0,0 +1,23 @@
+import ProvenZk.Gates
+import ProvenZk.Ext.Vector
+
+set_option linter.unusedVariables false
+
+namespace GenericCallCircuit
+
+def Order : ℕ := 0x30644e72e131a029b85045b68181585d2833e84879b9709143e1f593f0000001
+variable [Fact (Nat.Prime Order)]
+abbrev F := ZMod Order
+abbrev Gates := GatesGnark9 Order
+
+def Spread_2 (In: Vector F 2) (k: Vector (Vector (Vector (Vector F 2) 1) 1) 2 -> Prop): Prop :=
+    ∃gate_0, gate_0 = Gates.mul In[0] (2:F) ∧
+    ∃gate_1, gate_1 = Gates.mul In[1] (2:F) ∧
+    k vec![vec![vec![In]], vec![vec![vec![gate_0, gate_1]]]]
+
+def circuit (In: Vector F 2) (Out: F): Prop :=
+    Spread_2 In fun gate_0 =>
+    Gates.eq gate_0[1][0][0][1] Out ∧
+    True
+
+end GenericCallCircuit
\ No newline at end of file
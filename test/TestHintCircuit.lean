@@ -0,0 +1,27 @@
+import ProvenZk.Gates
+import ProvenZk.Ext.Vector
+
+set_option linter.unusedVariables false
+
+namespace HintCircuit
+
+def Order : ℕ := 0x30644e72e131a029b85045b68181585d2833e84879b9709143e1f593f0000001
+variable [Fact (Nat.Prime Order)]
+abbrev F := ZMod Order
+abbrev Gates := GatesGnark9 Order
+
+def DivMod (Dividend: F) (Divisor: F) (k: Vector F 2 -> Prop): Prop :=
+    ∃gate_0: Vector F 2, -- hint test_test.divModHint
+    ∃gate_1, gate_1 = Gates.mul gate_0[0] Divisor ∧
+    ∃gate_2, gate_2 = Gates.add gate_1 gate_0[1] ∧
+    Gates.eq Dividend gate_2 ∧
+    k gate_0
+
+def circuit (Dividend: F) (Divisor: F) (Quotient: F): Prop :=
+    DivMod Dividend Divisor fun gate_0 =>
+    Gates.eq gate_0[0] Quotient ∧
+    ∃gate_2: Vector F 2, -- hint test_test.divModHint
+    Gates.eq gate_2[0] Quotient ∧
+    True
+
+end HintCircuit
\ No newline at end of file
@@ -0,0 +1,25 @@
+import ProvenZk.Gates
+import ProvenZk.Ext.Vector
+
+set_option linter.unusedVariables false
+
+namespace InlineCircuit
+
+def Order : ℕ := 0x30644e72e131a029b85045b68181585d2833e84879b9709143e1f593f0000001
+variable [Fact (Nat.Prime Order)]
+abbrev F := ZMod Order
+abbrev Gates := GatesGnark9 Order
+
+def DummyHash (In_1: F) (In_2: F) (k: F -> Prop): Prop :=
+    ∃gate_0, gate_0 = Gates.mul In_1 In_2 ∧
+    k gate_0
+
+def circuit (In: Vector F 2) (Out: F): Prop :=
+    ∃gate_0, gate_0 = Gates.neg In[0] ∧
+    Gates.ne In[1] gate_0 ∧
+    DummyHash In[1] gate_0 fun gate_2 =>
+    DummyHash gate_2 Out fun gate_3 =>
+    Gates.eq gate_3 (0:F) ∧
+    True
+
+end InlineCircuit
\ No newline at end of file
@@ -0,0 +1,25 @@
+import ProvenZk.Gates
+import ProvenZk.Ext.Vector
+
+set_option linter.unusedVariables false
+
+namespace InlineCircuit
+
+def Order : ℕ := 0x30644e72e131a029b85045b68181585d2833e84879b9709143e1f593f0000001
+variable [Fact (Nat.Prime Order)]
+abbrev F := ZMod Order
+abbrev Gates := GatesGnark9 Order
+
+def HashPair_2 (Pair: Vector F 2) (k: F -> Prop): Prop :=
+    ∃gate_0, gate_0 = Gates.neg Pair[0] ∧
+    Gates.ne Pair[1] gate_0 ∧
+    ∃gate_2, gate_2 = Gates.mul Pair[1] gate_0 ∧
+    k gate_2
+
+def circuit (In: Vector F 2) (Out: F): Prop :=
+    HashPair_2 In fun gate_0 =>
+    ∃gate_1, gate_1 = Gates.mul gate_0 Out ∧
+    Gates.eq gate_1 (0:F) ∧
+    True
+
+end InlineCircuit
\ No newline at end of file
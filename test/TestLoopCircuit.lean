@@ -0,0 +1,41 @@
+import ProvenZk.Gates
+import ProvenZk.Ext.Vector
+
+set_option linter.unusedVariables false
+
+namespace LoopCircuit
+
+def Order : ℕ := 0x30644e72e131a029b85045b68181585d2833e84879b9709143e1f593f0000001
+variable [Fact (Nat.Prime Order)]
+abbrev F := ZMod Order
+abbrev Gates := GatesGnark9 Order
+
+def ScaledSumProd_6_loop_0 (Values: List F) (carry_0: F) (carry_1: F) (Scale: F) (k: Vector F 2 -> Prop): Prop :=
+    match Values with
+    | Values_i :: Values =>
+        ∃gate_0, gate_0 = Gates.mul Values_i Scale ∧
+        ∃gate_1, gate_1 = Gates.add carry_0 gate_0 ∧
+        ∃gate_2, gate_2 = Gates.mul carry_1 Values_i ∧
+        ScaledSumProd_6_loop_0 Values gate_1 gate_2 Scale k
+    | _ => k vec![carry_0, carry_1]
+
+def ScaledSumProd_6 (Values: Vector F 6) (Scale: F) (k: Vector F 2 -> Prop): Prop :=
+    ∃gate_0, gate_0 = Gates.mul Values[1] Scale ∧
+    ScaledSumProd_6_loop_0 ((Values.toList.drop 2).take 3) gate_0 Values[1] Scale fun gate_1 =>
+    k gate_1
+
+def circuit_loop_0 (Bits: List F) : Prop :=
+    match Bits with
+    | Bits_i :: Bits =>
+        Gates.is_bool Bits_i ∧
+        circuit_loop_0 Bits
+    | _ => True
+
+def circuit (Values: Vector F 6) (Scale: F) (Sum: F) (Prod: F) (Bits: Vector F 4): Prop :=
+    ScaledSumProd_6 Values Scale fun gate_0 =>
+    Gates.eq gate_0[0] Sum ∧
+    Gates.eq gate_0[1] Prod ∧
+    circuit_loop_0 Bits.toList ∧
+    True
+
+end LoopCircuit
\ No newline at end of file
@@ -0,0 +1,31 @@
+import ProvenZk.Gates
+import ProvenZk.Ext.Vector
+
+set_option linter.unusedVariables false
+
+namespace MerkleRecover
+
+def Order : ℕ := 0x30644e72e131a029b85045b68181585d2833e84879b9709143e1f593f0000001
+variable [Fact (Nat.Prime Order)]
+abbrev F := ZMod Order
+abbrev Gates := GatesGnark9 Order
+
+def DummyHash (In_1: F) (In_2: F) (k: F -> Prop): Prop :=
+    ∃gate_0, gate_0 = Gates.mul In_1 In_2 ∧
+    k gate_0
+
+def circuit_loop_0 (Proof: List F) (Path: List F) (carry_0: F) (k: F -> Prop): Prop :=
+    match Proof, Path with
+    | Proof_i :: Proof, Path_i :: Path =>
+        DummyHash carry_0 Proof_i fun gate_0 =>
+        DummyHash Proof_i carry_0 fun gate_1 =>
+        ∃gate_2, Gates.select Path_i gate_1 gate_0 gate_2 ∧
+        circuit_loop_0 Proof Path gate_2 k
+    | _, _ => k carry_0
+
+def circuit (Root: F) (Element: F) (Path: Vector F 20) (Proof: Vector F 20): Prop :=
+    circuit_loop_0 Proof.toList Path.toList Element fun gate_0 =>
+    Gates.eq gate_0 Root ∧
+    True
+
+end MerkleRecover
\ No newline at end of file
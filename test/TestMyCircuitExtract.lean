@@ -0,0 +1,22 @@
+import ProvenZk.Gates
+import ProvenZk.Ext.Vector
+import Mathlib.Tactic
+
+set_option linter.unusedVariables false
+set_option maxHeartbeats 400000
+
+namespace Readme
+
+def Order : ℕ := 0x30644e72e131a029b85045b68181585d2833e84879b9709143e1f593f0000001
+variable [Fact (Nat.Prime Order)]
+abbrev F := ZMod Order
+abbrev Gates := GatesGnark9 Order
+
+
+
+def circuit (In_1: F) (In_2: F) (Out: F): Prop :=
+    ∃gate_0, gate_0 = Gates.add In_1 In_2 ∧
+    Gates.eq gate_0 Out ∧
+    True
+
+end Readme
\ No newline at end of file
@@ -0,0 +1,56 @@
+import ProvenZk.Gates
+import ProvenZk.Ext.Vector
+
+set_option linter.unusedVariables false
+
+namespace PartialPolymorphicCircuit
+
+def Order : ℕ := 0x30644e72e131a029b85045b68181585d2833e84879b9709143e1f593f0000001
+variable [Fact (Nat.Prime Order)]
+abbrev F := ZMod Order
+abbrev Gates := GatesGnark9 Order
+
+def ScaledBits {n_0 : ℕ} (Bits: Vector F n_0) (Factor: ℕ) (k: F -> Prop): Prop :=
+    ∃gate_0, Gates.from_binary Bits gate_0 ∧
+    ∃gate_1, gate_1 = Gates.mul gate_0 Factor ∧
+    k gate_1
+
+def SquaredBits {n_0 : ℕ} (Bits: Vector F n_0) (k: F -> Prop): Prop :=
+    ∃gate_0, Gates.from_binary Bits gate_0 ∧
+    ∃gate_1, gate_1 = Gates.mul gate_0 (4:F) ∧
+    k gate_1
+
+def SquaredBits_generic_1 {n_0 : ℕ} (Bits: Vector F n_0) (k: F -> Prop): Prop :=
+    ∃gate_0, Gates.from_binary Bits gate_0 ∧
+    ∃gate_1, gate_1 = Gates.mul gate_0 (9:F) ∧
+    k gate_1
+
+def SquaredBits_4_5 (Bits: Vector F 4) (k: F -> Prop): Prop :=
+    ∃gate_0, Gates.from_binary Bits gate_0 ∧
+    ∃gate_1, gate_1 = Gates.mul gate_0 (25:F) ∧
+    k gate_1
+
+def circuit (Short: Vector F 4) (Long: Vector F 8): Prop :=
+    ScaledBits Short 2 fun gate_0 =>
+    ScaledBits Long 2 fun gate_1 =>
+    ∃gate_2, gate_2 = Gates.add gate_0 gate_1 ∧
+    SquaredBits Short fun gate_3 =>
+    ∃gate_4, gate_4 = Gates.add gate_2 gate_3 ∧
+    SquaredBits Long fun gate_5 =>
+    ∃gate_6, gate_6 = Gates.add gate_4 gate_5 ∧
+    ScaledBits Short 3 fun gate_7 =>
+    ∃gate_8, gate_8 = Gates.add gate_6 gate_7 ∧
+    ScaledBits Long 3 fun gate_9 =>
+    ∃gate_10, gate_10 = Gates.add gate_8 gate_9 ∧
+    SquaredBits_generic_1 Short fun gate_11 =>
+    ∃gate_12, gate_12 = Gates.add gate_10 gate_11 ∧
+    SquaredBits_generic_1 Long fun gate_13 =>
+    ∃gate_14, gate_14 = Gates.add gate_12 gate_13 ∧
+    ScaledBits Short 5 fun gate_15 =>
+    ∃gate_16, gate_16 = Gates.add gate_14 gate_15 ∧
+    SquaredBits_4_5 Short fun gate_17 =>
+    ∃gate_18, gate_18 = Gates.add gate_16 gate_17 ∧
+    Gates.eq gate_18 (0:F) ∧
+    True
+
+end PartialPolymorphicCircuit
\ No newline at end of file
@@ -0,0 +1,38 @@
+import ProvenZk.Gates
+import ProvenZk.Ext.Vector
+
+set_option linter.unusedVariables false
+
+namespace PolymorphicCircuit
+
+def Order : ℕ := 0x30644e72e131a029b85045b68181585d2833e84879b9709143e1f593f0000001
+variable [Fact (Nat.Prime Order)]
+abbrev F := ZMod Order
+abbrev Gates := GatesGnark9 Order
+
+def BitsCheck {n_0 : ℕ} (Bits: Vector F n_0) (Value: F) : Prop :=
+    ∃gate_0, Gates.from_binary Bits gate_0 ∧
+    Gates.eq gate_0 Value ∧
+    True
+
+def DoubleBitsCheck {n_0 : ℕ} (Bits: Vector F n_0) (Value: F) (k: Vector F n_0 -> Prop): Prop :=
+    BitsCheck Bits Value ∧
+    BitsCheck Bits Value ∧
+    k Bits
+
+def FirstBit_8 (Bits: Vector F 8) (k: F -> Prop): Prop :=
+    k Bits[0]
+
+def FirstBit_16 (Bits: Vector F 16) (k: F -> Prop): Prop :=
+    k Bits[0]
+
+def circuit (Short: Vector F 8) (Long: Vector F 16) (A: F) (B: F): Prop :=
+    DoubleBitsCheck Short A fun gate_0 =>
+    DoubleBitsCheck Long B fun gate_1 =>
+    FirstBit_8 gate_0 fun gate_2 =>
+    Gates.eq gate_2 (0:F) ∧
+    FirstBit_16 gate_1 fun gate_4 =>
+    Gates.eq gate_4 (0:F) ∧
+    True
+
+end PolymorphicCircuit
\ No newline at end of file
@@ -0,0 +1,25 @@
+import ProvenZk.Gates
+import ProvenZk.Ext.Vector
+
+set_option linter.unusedVariables false
+
+namespace PrintlnCircuit
+
+def Order : ℕ := 0x30644e72e131a029b85045b68181585d2833e84879b9709143e1f593f0000001
+variable [Fact (Nat.Prime Order)]
+abbrev F := ZMod Order
+abbrev Gates := GatesGnark9 Order
+
+def PrintlnGadget_3 (In: Vector F 3) (k: F -> Prop): Prop :=
+    ∃gate_0, gate_0 = Gates.add In[0] In[1] ∧
+    ∃gate_0, gate_0 = Gates.add gate_0 In[2] ∧
+    -- sum of In is gate_0
+    k gate_0
+
+def circuit (In: Vector F 3) (Out: F): Prop :=
+    PrintlnGadget_3 In fun gate_0 =>
+    -- expected result: Out (42:F)
+    Gates.eq gate_0 Out ∧
+    True
+
+end PrintlnCircuit
\ No newline at end of file
@@ -0,0 +1,26 @@
+import ProvenZk.Gates
+import ProvenZk.Ext.Vector
+
+set_option linter.unusedVariables false
+
+namespace RaggedCircuit
+
+def Order : ℕ := 0x30644e72e131a029b85045b68181585d2833e84879b9709143e1f593f0000001
+variable [Fact (Nat.Prime Order)]
+abbrev F := ZMod Order
+abbrev Gates := GatesGnark9 Order
+
+def RaggedSum_3_2 (Paths_0: Vector F 3) (Paths_1: Vector F 2) (k: Vector F 2 -> Prop): Prop :=
+    ∃gate_0, gate_0 = Gates.add Paths_0[0] Paths_0[1] ∧
+    ∃gate_0, gate_0 = Gates.add gate_0 Paths_0[2] ∧
+    ∃gate_1, gate_1 = Gates.add Paths_1[0] Paths_1[1] ∧
+    k vec![gate_0, gate_1]
+
+def circuit (Paths_0: Vector F 3) (Paths_1: Vector F 2) (Roots: Vector F 2): Prop :=
+    RaggedSum_3_2 Paths_0 Paths_1 fun gate_0 =>
+    Gates.eq gate_0[0] Roots[0] ∧
+    Gates.eq gate_0[1] Roots[1] ∧
+    Gates.eq Paths_1[1] Paths_0[2] ∧
+    True
+
+end RaggedCircuit
\ No newline at end of file
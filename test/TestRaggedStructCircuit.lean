@@ -0,0 +1,43 @@
+import ProvenZk.Gates
+import ProvenZk.Ext.Vector
+
+set_option linter.unusedVariables false
+
+namespace RaggedStructCircuit
+
+def Order : ℕ := 0x30644e72e131a029b85045b68181585d2833e84879b9709143e1f593f0000001
+variable [Fact (Nat.Prime Order)]
+abbrev F := ZMod Order
+abbrev Gates := GatesGnark9 Order
+
+structure MerkleProof_1 where
+    Leaf : F
+    Path : Vector F 1
+
+structure MerkleProof_3 where
+    Leaf : F
+    Path : Vector F 3
+
+def ProofSum_1 (Proof: MerkleProof_1) (k: F -> Prop): Prop :=
+    ∃gate_0, gate_0 = Gates.add Proof.Leaf Proof.Path[0] ∧
+    k gate_0
+
+def ProofSum_3 (Proof: MerkleProof_3) (k: F -> Prop): Prop :=
+    ∃gate_0, gate_0 = Gates.add Proof.Leaf Proof.Path[0] ∧
+    ∃gate_1, gate_1 = Gates.add gate_0 Proof.Path[1] ∧
+    ∃gate_2, gate_2 = Gates.add gate_1 Proof.Path[2] ∧
+    k gate_2
+
+def BatchProofSum_1_3 (Proofs_0: MerkleProof_1) (Proofs_1: MerkleProof_3) (k: Vector F 2 -> Prop): Prop :=
+    ProofSum_1 Proofs_0 fun gate_0 =>
+    ProofSum_3 Proofs_1 fun gate_1 =>
+    k vec![gate_0, gate_1]
+
+def circuit (Proofs_0: MerkleProof_1) (Proofs_1: MerkleProof_3) (Roots: Vector F 2): Prop :=
+    BatchProofSum_1_3 Proofs_0 Proofs_1 fun gate_0 =>
+    Gates.eq gate_0[0] Roots[0] ∧
+    Gates.eq gate_0[1] Roots[1] ∧
+    Gates.eq Proofs_1.Path[2] Proofs_0.Path[0] ∧
+    True
+
+end RaggedStructCircuit
\ No newline at end of file
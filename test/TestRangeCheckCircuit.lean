@@ -0,0 +1,27 @@
+import ProvenZk.Gates
+import ProvenZk.Ext.Vector
+
+set_option linter.unusedVariables false
+
+namespace RangeCheckCircuit
+
+def Order : ℕ := 0x30644e72e131a029b85045b68181585d2833e84879b9709143e1f593f0000001
+variable [Fact (Nat.Prime Order)]
+abbrev F := ZMod Order
+abbrev Gates := GatesGnark9 Order
+
+namespace GatesExt
+def range_check (a : F) (n : ℕ) : Prop := a.val < 2^n
+end GatesExt
+
+def RangeCheckGadget_64 (In: F) : Prop :=
+    GatesExt.range_check In 64 ∧
+    True
+
+def circuit (Amount: F) (Balance: F): Prop :=
+    RangeCheckGadget_64 Amount ∧
+    ∃gate_1, gate_1 = Gates.sub Balance Amount ∧
+    GatesExt.range_check gate_1 64 ∧
+    True
+
+end RangeCheckCircuit
\ No newline at end of file
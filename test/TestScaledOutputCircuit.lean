@@ -0,0 +1,27 @@
+import ProvenZk.Gates
+import ProvenZk.Ext.Vector
+
+set_option linter.unusedVariables false
+
+namespace ScaledOutputCircuit
+
+def Order : ℕ := 0x30644e72e131a029b85045b68181585d2833e84879b9709143e1f593f0000001
+variable [Fact (Nat.Prime Order)]
+abbrev F := ZMod Order
+abbrev Gates := GatesGnark9 Order
+
+structure Scaled_2 where
+    Coords : Vector F 2
+
+def Scale_3 (X: F) (Y: F) (k: Scaled_2 -> Prop): Prop :=
+    ∃gate_0, gate_0 = Gates.mul X (3:F) ∧
+    ∃gate_1, gate_1 = Gates.mul Y (3:F) ∧
+    k { Coords := vec![gate_0, gate_1] }
+
+def circuit (X: F) (Y: F) (Out: F): Prop :=
+    Scale_3 X Y fun gate_0 =>
+    ∃gate_1, gate_1 = Gates.add gate_0.Coords[0] gate_0.Coords[1] ∧
+    Gates.eq gate_1 Out ∧
+    True
+
+end ScaledOutputCircuit
\ No newline at end of file
@@ -0,0 +1,29 @@
+import ProvenZk.Gates
+import ProvenZk.Ext.Vector
+
+set_option linter.unusedVariables false
+
+namespace ShiftedLoopCircuit
+
+def Order : ℕ := 0x30644e72e131a029b85045b68181585d2833e84879b9709143e1f593f0000001
+variable [Fact (Nat.Prime Order)]
+abbrev F := ZMod Order
+abbrev Gates := GatesGnark9 Order
+
+
+
+def circuit_loop_0 (Values: List F) (Values_2: List F) (carry_0: F) (k: F -> Prop): Prop :=
+    match Values, Values_2 with
+    | Values_i :: Values, Values_2_i :: Values_2 =>
+        ∃gate_0, gate_0 = Gates.mul Values_i Values_2_i ∧
+        ∃gate_1, gate_1 = Gates.add carry_0 gate_0 ∧
+        circuit_loop_0 Values Values_2 gate_1 k
+    | _, _ => k carry_0
+
+def circuit (Values: Vector F 5) (Sum: F): Prop :=
+    ∃gate_0, gate_0 = Gates.mul Values[0] Values[1] ∧
+    circuit_loop_0 ((Values.toList.drop 1).take 3) (Values.toList.drop 2) gate_0 fun gate_1 =>
+    Gates.eq gate_1 Sum ∧
+    True
+
+end ShiftedLoopCircuit
\ No newline at end of file
@@ -0,0 +1,46 @@
+import ProvenZk.Gates
+import ProvenZk.Ext.Vector
+
+set_option linter.unusedVariables false
+
+namespace StructInputCircuit
+
+def Order : ℕ := 0x30644e72e131a029b85045b68181585d2833e84879b9709143e1f593f0000001
+variable [Fact (Nat.Prime Order)]
+abbrev F := ZMod Order
+abbrev Gates := GatesGnark9 Order
+
+structure Point where
+    X : F
+    Y : F
+
+structure MerkleProof_3 where
+    Leaf : F
+    Path : Vector F 3
+
+structure Claim_3 where
+    Owner : Point
+    Proof : MerkleProof_3
+
+def ProofSum_3 (Proof: MerkleProof_3) (k: F -> Prop): Prop :=
+    ∃gate_0, gate_0 = Gates.add Proof.Leaf Proof.Path[0] ∧
+    ∃gate_1, gate_1 = Gates.add gate_0 Proof.Path[1] ∧
+    ∃gate_2, gate_2 = Gates.add gate_1 Proof.Path[2] ∧
+    k gate_2
+
+def ClaimCheck_2_3 (Claims: Vector Claim_3 2) (Owner: Point) (k: Vector F 2 -> Prop): Prop :=
+    Gates.eq Claims[0].Owner.X Owner.X ∧
+    ProofSum_3 Claims[0].Proof fun gate_1 =>
+    Gates.eq Claims[1].Owner.X Owner.X ∧
+    ProofSum_3 Claims[1].Proof fun gate_3 =>
+    k vec![gate_1, gate_3]
+
+def circuit (Owner: Point) (Claims: Vector Claim_3 2) (Sums: Vector F 2): Prop :=
+    ClaimCheck_2_3 Claims Owner fun gate_0 =>
+    Gates.eq gate_0[0] Sums[0] ∧
+    Gates.eq gate_0[1] Sums[1] ∧
+    ProofSum_3 { Leaf := Owner.Y, Path := Claims[0].Proof.Path } fun gate_3 =>
+    Gates.eq gate_3 Sums[0] ∧
+    True
+
+end StructInputCircuit
\ No newline at end of file
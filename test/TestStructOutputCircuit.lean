@@ -0,0 +1,43 @@
+import ProvenZk.Gates
+import ProvenZk.Ext.Vector
+
+set_option linter.unusedVariables false
+
+namespace StructOutputCircuit
+
+def Order : ℕ := 0x30644e72e131a029b85045b68181585d2833e84879b9709143e1f593f0000001
+variable [Fact (Nat.Prime Order)]
+abbrev F := ZMod Order
+abbrev Gates := GatesGnark9 Order
+
+structure Point where
+    X : F
+    Y : F
+
+structure Segment_2_3 where
+    Ends : Vector Point 2
+    Length : F
+    Steps : Vector F 3
+
+def Double (X: F) (Y: F) (k: Point -> Prop): Prop :=
+    ∃gate_0, gate_0 = Gates.add X X ∧
+    ∃gate_1, gate_1 = Gates.add Y Y ∧
+    k { X := gate_0, Y := gate_1 }
+
+def Walk_3 (X: F) (Y: F) (Steps: Vector F 3) (k: Segment_2_3 -> Prop): Prop :=
+    ∃gate_0, gate_0 = Gates.add X Steps[0] ∧
+    Double gate_0 Y fun gate_1 =>
+    ∃gate_2, gate_2 = Gates.add gate_1.X Steps[1] ∧
+    Double gate_2 gate_1.Y fun gate_3 =>
+    ∃gate_4, gate_4 = Gates.add gate_3.X Steps[2] ∧
+    Double gate_4 gate_3.Y fun gate_5 =>
+    ∃gate_6, gate_6 = Gates.sub gate_5.X X ∧
+    k { Ends := vec![{ X := X, Y := Y }, gate_5], Length := gate_6, Steps := Steps }
+
+def circuit (X: F) (Y: F) (Steps: Vector F 3) (Out: F): Prop :=
+    Walk_3 X Y Steps fun gate_0 =>
+    Gates.eq gate_0.Ends[1].Y Out ∧
+    Gates.eq gate_0.Length gate_0.Steps[0] ∧
+    True
+
+end StructOutputCircuit
\ No newline at end of file